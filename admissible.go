@@ -0,0 +1,43 @@
+package gcra
+
+import "time"
+
+// LargestAdmissible will determine and consume the largest cost in the range
+// [0, maxCost] that is admitted by the bucket. Like EffectiveBurst, the cost
+// may be drawn from the overdraft and the base cost is charged on top of the
+// returned cost. If not even a zero cost query is admitted, the bucket is
+// returned unchanged with the limited result. Requests are never rejected
+// early.
+func LargestAdmissible(now time.Time, bucket Bucket, maxCost int64, opts Options) (int64, Bucket, Result, error) {
+	// check arguments
+	if maxCost < 0 {
+		return 0, bucket, Result{}, ErrInvalidParameter
+	}
+
+	// ignore early rejections
+	opts.EarlyRejectAt = 0
+
+	// determine cost
+	cost := maxCost
+	if effective := EffectiveBurst(now, bucket, opts); cost > effective {
+		cost = effective
+	}
+
+	// compute GCRA
+	newBucket, result, err := Compute(now, bucket, cost, opts)
+	if err != nil {
+		return 0, bucket, Result{}, err
+	}
+
+	return cost, newBucket, result, nil
+}
+
+// ComputePartial will perform the GCRA like Compute but consume the largest
+// admissible amount of tokens up to the requested amount instead of limiting
+// the request. It returns the granted amount and only reports a limited result
+// if not even a zero cost query is admitted. It is equivalent to
+// LargestAdmissible with the bucket returned first.
+func ComputePartial(now time.Time, bucket Bucket, requested int64, opts Options) (Bucket, int64, Result, error) {
+	granted, bucket, result, err := LargestAdmissible(now, bucket, requested, opts)
+	return bucket, granted, result, err
+}
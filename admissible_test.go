@@ -0,0 +1,232 @@
+package gcra
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLargestAdmissible(t *testing.T) {
+	opts := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+	}
+
+	bucket := MustGenerate(now, 4, opts)
+
+	cost, bucket, result, err := LargestAdmissible(now, bucket, 2, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), cost)
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 2,
+		RetryIn:   0,
+		ResetIn:   800 * time.Millisecond,
+		Consumed:  2,
+		Balance:   2,
+	}, result)
+
+	cost, bucket, result, err = LargestAdmissible(now, bucket, 5, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), cost)
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 0,
+		RetryIn:   0,
+		ResetIn:   time.Second,
+		Consumed:  2,
+		Balance:   0,
+	}, result)
+
+	cost, newBucket, result, err := LargestAdmissible(now, bucket, 5, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), cost)
+	assert.Equal(t, bucket, newBucket)
+	assert.Equal(t, Result{
+		Limited:   true,
+		Remaining: 0,
+		RetryIn:   0,
+		ResetIn:   time.Second,
+		Balance:   0,
+		Reason:    EmptyQuery,
+	}, result)
+
+	cost, _, result, err = LargestAdmissible(now, Bucket{}, 20, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), cost)
+	assert.False(t, result.Limited)
+}
+
+func TestLargestAdmissibleRounding(t *testing.T) {
+	opts := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+	}
+
+	// leave two and a half tokens
+	bucket := Bucket(now.Add(750 * time.Millisecond))
+
+	cost, _, result, err := LargestAdmissible(now, bucket, 10, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), cost)
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 1,
+		RetryIn:   0,
+		ResetIn:   950 * time.Millisecond,
+		Consumed:  2,
+		Balance:   1,
+	}, result)
+}
+
+func TestLargestAdmissibleUnlimited(t *testing.T) {
+	cost, _, result, err := LargestAdmissible(now, Bucket{}, 1000, Options{
+		Burst:  Unlimited,
+		Rate:   1,
+		Period: time.Second,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000), cost)
+	assert.Equal(t, Result{
+		Remaining: math.MaxInt64,
+		Balance:   math.MaxInt64,
+	}, result)
+}
+
+func TestLargestAdmissibleErrors(t *testing.T) {
+	_, _, _, err := LargestAdmissible(now, Bucket{}, -1, Options{Burst: 1, Rate: 1, Period: 1})
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, _, _, err = LargestAdmissible(now, Bucket{}, 1, Options{Burst: 0, Rate: 1, Period: 1})
+	assert.Equal(t, ErrInvalidParameter, err)
+}
+
+func TestLargestAdmissibleBaseCost(t *testing.T) {
+	opts := Options{
+		Burst:    10,
+		Rate:     10,
+		Period:   time.Second,
+		BaseCost: 2,
+	}
+
+	// fresh bucket
+	cost, bucket, result, err := LargestAdmissible(now, Bucket{}, 20, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), cost)
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 0,
+		RetryIn:   0,
+		ResetIn:   time.Second,
+		Consumed:  10,
+		Balance:   0,
+	}, result)
+
+	// partially drained bucket
+	cost, _, result, err = LargestAdmissible(now.Add(500*time.Millisecond), bucket, 20, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), cost)
+	assert.False(t, result.Limited)
+	assert.Equal(t, int64(5), result.Consumed)
+
+	// not enough for the base cost
+	cost, newBucket, result, err := LargestAdmissible(now.Add(100*time.Millisecond), bucket, 20, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), cost)
+	assert.Equal(t, bucket, newBucket)
+	assert.False(t, result.Limited)
+	assert.Equal(t, int64(1), result.Remaining)
+
+	// partial computation
+	_, granted, result, err := ComputePartial(now, Bucket{}, 5, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), granted)
+	assert.Equal(t, int64(3), result.Remaining)
+}
+
+func TestLargestAdmissibleEarlyRejectAt(t *testing.T) {
+	opts := Options{
+		Burst:         50,
+		Rate:          10,
+		Period:        time.Second,
+		EarlyRejectAt: 50,
+	}
+
+	// requests are never rejected early
+	for i := 0; i < 100; i++ {
+		at := now.Add(time.Duration(i) * time.Millisecond)
+		cost, bucket, result, err := LargestAdmissible(at, MustGenerate(at, 30, opts), 40, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(30), cost)
+		assert.False(t, result.Limited)
+		assert.Equal(t, int64(30), result.Consumed)
+		assert.Equal(t, 5*time.Second, time.Time(bucket).Sub(at))
+	}
+}
+
+func TestLargestAdmissibleOverdraft(t *testing.T) {
+	opts := Options{
+		Burst:          10,
+		Rate:           10,
+		Period:         time.Second,
+		AllowOverdraft: 5,
+	}
+
+	// the overdraft is used like EffectiveBurst
+	for _, bucket := range []Bucket{{}, MustGenerate(now, 4, opts)} {
+		effective := EffectiveBurst(now, bucket, opts)
+		cost, _, result, err := LargestAdmissible(now, bucket, 100, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, effective, cost)
+		assert.False(t, result.Limited)
+	}
+
+	// the overdraft must be repaid before further requests are admitted
+	cost, bucket, _, err := LargestAdmissible(now, Bucket{}, 100, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(15), cost)
+	cost, _, result, err := LargestAdmissible(now, bucket, 100, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), cost)
+	assert.True(t, result.Limited)
+}
+
+func TestComputePartial(t *testing.T) {
+	opts := Options{
+		Burst:  100,
+		Rate:   100,
+		Period: time.Second,
+	}
+
+	// fully admitted
+	bucket, granted, result, err := ComputePartial(now, Bucket{}, 60, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(60), granted)
+	assert.False(t, result.Limited)
+	assert.Equal(t, int64(40), result.Remaining)
+
+	// partially admitted
+	bucket, granted, result, err = ComputePartial(now, bucket, 100, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(40), granted)
+	assert.Equal(t, Result{
+		Remaining: 0,
+		ResetIn:   time.Second,
+		Consumed:  40,
+		Balance:   0,
+	}, result)
+
+	// zero admitted
+	newBucket, granted, result, err := ComputePartial(now, bucket, 100, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), granted)
+	assert.Equal(t, bucket, newBucket)
+	assert.True(t, result.Limited)
+
+	_, _, _, err = ComputePartial(now, bucket, -1, opts)
+	assert.Equal(t, ErrInvalidParameter, err)
+}
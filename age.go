@@ -0,0 +1,25 @@
+package gcra
+
+import "time"
+
+// Age returns the duration until the TAT of the bucket is reached, i.e. the
+// duration the bucket still needs to become full again. Unlike ResetIn, it is
+// defined for all buckets and options and yields zero for a zero or fully reset
+// bucket unless it warms up. The duration is truncated to the resolution of the
+// options.
+func Age(now time.Time, bucket Bucket, opts Options) time.Duration {
+	// handle zero bucket
+	bucket = opts.initial(now, bucket)
+	if time.Time(bucket).IsZero() {
+		return 0
+	}
+
+	// compute difference
+	res := opts.resolution()
+	diff := toUnits(time.Time(bucket), res) - toUnits(now, res)
+	if diff <= 0 {
+		return 0
+	}
+
+	return time.Duration(diff) * res
+}
@@ -0,0 +1,40 @@
+package gcra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAge(t *testing.T) {
+	opts := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+	}
+
+	assert.Equal(t, time.Duration(0), Age(now, Bucket{}, opts))
+	assert.Equal(t, time.Second, Age(now, MustGenerate(now, 0, opts), opts))
+	assert.Equal(t, 700*time.Millisecond, Age(now, MustGenerate(now, 3, opts), opts))
+	assert.Equal(t, time.Duration(0), Age(now, MustGenerate(now, 10, opts), opts))
+	assert.Equal(t, time.Duration(0), Age(now.Add(time.Minute), MustGenerate(now, 0, opts), opts))
+
+	opts.Resolution = time.Second
+	assert.Equal(t, time.Second, Age(now, Bucket(now.Add(1500*time.Millisecond)), opts))
+}
+
+func TestAgeWarmUp(t *testing.T) {
+	opts := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+		WarmUp: 300 * time.Millisecond,
+	}
+
+	assert.Equal(t, 300*time.Millisecond, Age(now, Bucket{}, opts))
+	assert.Equal(t, 700*time.Millisecond, Age(now, MustGenerate(now, 3, opts), opts))
+
+	opts.WarmUp = time.Hour
+	assert.Equal(t, time.Second, Age(now, Bucket{}, opts))
+}
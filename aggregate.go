@@ -0,0 +1,51 @@
+package gcra
+
+import "math"
+
+// Aggregate combines the results of multiple tiers that limit the same request
+// into a single result. The request is limited if any tier limited it. The
+// RetryIn duration is the maximum across the limiting tiers, as retrying
+// earlier would not help, while ResetIn is the maximum across all tiers. The
+// remaining tokens and balance are the minimum across all tiers. Consumed is
+// zero if any tier limited the request and the minimum otherwise. The reason is
+// taken from the limiting tier with the largest RetryIn. No results yield a
+// zero result.
+func Aggregate(results ...Result) Result {
+	// check results
+	if len(results) == 0 {
+		return Result{}
+	}
+
+	// aggregate results
+	aggregate := Result{
+		Remaining: math.MaxInt64,
+		Consumed:  math.MaxInt64,
+		Balance:   math.MaxInt64,
+	}
+	for _, result := range results {
+		if result.Limited && (!aggregate.Limited || result.RetryIn > aggregate.RetryIn) {
+			aggregate.Limited = true
+			aggregate.RetryIn = result.RetryIn
+			aggregate.Reason = result.Reason
+		}
+		if result.ResetIn > aggregate.ResetIn {
+			aggregate.ResetIn = result.ResetIn
+		}
+		if result.Remaining < aggregate.Remaining {
+			aggregate.Remaining = result.Remaining
+		}
+		if result.Consumed < aggregate.Consumed {
+			aggregate.Consumed = result.Consumed
+		}
+		if result.Balance < aggregate.Balance {
+			aggregate.Balance = result.Balance
+		}
+	}
+
+	// reset consumed if limited
+	if aggregate.Limited {
+		aggregate.Consumed = 0
+	}
+
+	return aggregate
+}
@@ -0,0 +1,79 @@
+package gcra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregate(t *testing.T) {
+	perSecond := Options{Burst: 2, Rate: 2, Period: time.Second}
+	perMinute := Options{Burst: 3, Rate: 3, Period: time.Minute}
+
+	var second, minute Bucket
+	var results []Result
+	for i := 0; i < 4; i++ {
+		var a, b Result
+		second, a = MustCompute(now, second, 1, perSecond)
+		minute, b = MustCompute(now, minute, 1, perMinute)
+		results = []Result{a, b}
+	}
+
+	// both tiers reject with different retry values
+	assert.True(t, results[0].Limited)
+	assert.True(t, results[1].Limited)
+	assert.Equal(t, 500*time.Millisecond, results[0].RetryIn)
+	assert.Equal(t, 20*time.Second, results[1].RetryIn)
+
+	assert.Equal(t, Result{
+		Limited:   true,
+		Remaining: 0,
+		RetryIn:   20 * time.Second,
+		ResetIn:   time.Minute,
+		Balance:   0,
+		Reason:    InsufficientTokens,
+	}, Aggregate(results...))
+
+	// only one tier rejects
+	assert.Equal(t, Result{
+		Limited:   true,
+		Remaining: 1,
+		RetryIn:   time.Second,
+		ResetIn:   5 * time.Second,
+		Balance:   1,
+		Reason:    InsufficientTokens,
+	}, Aggregate(Result{
+		Remaining: 3,
+		ResetIn:   5 * time.Second,
+		Consumed:  1,
+		Balance:   3,
+	}, Result{
+		Limited:   true,
+		Remaining: 1,
+		RetryIn:   time.Second,
+		ResetIn:   2 * time.Second,
+		Balance:   1,
+		Reason:    InsufficientTokens,
+	}))
+
+	// no tier rejects
+	assert.Equal(t, Result{
+		Remaining: 1,
+		ResetIn:   5 * time.Second,
+		Consumed:  1,
+		Balance:   1,
+	}, Aggregate(Result{
+		Remaining: 3,
+		ResetIn:   5 * time.Second,
+		Consumed:  1,
+		Balance:   3,
+	}, Result{
+		Remaining: 1,
+		ResetIn:   2 * time.Second,
+		Consumed:  1,
+		Balance:   1,
+	}))
+
+	assert.Equal(t, Result{}, Aggregate())
+}
@@ -0,0 +1,25 @@
+package gcra
+
+import "time"
+
+// AllowedInWindow returns an estimate of the tokens consumed from the bucket
+// over the trailing window, computed as the burst minus the remaining tokens.
+// As the GCRA does not retain a history, consumption that has already been
+// regenerated is not accounted for. Invalid options yield zero.
+func AllowedInWindow(now time.Time, bucket Bucket, opts Options) int64 {
+	// handle unlimited
+	if opts.Burst == Unlimited {
+		return 0
+	}
+
+	// query bucket
+	_, result, err := Compute(now, bucket, 0, opts)
+	if err != nil {
+		return 0
+	}
+
+	// determine burst
+	burst := opts.burst(false)
+
+	return clamp(burst-result.Remaining, 0, burst)
+}
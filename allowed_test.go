@@ -0,0 +1,46 @@
+package gcra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowedInWindow(t *testing.T) {
+	opts := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+	}
+
+	assert.Equal(t, int64(0), AllowedInWindow(now, Bucket{}, opts))
+
+	for count := int64(0); count <= 10; count++ {
+		bucket := MustGenerate(now, count, opts)
+		_, result := MustCompute(now, bucket, 0, opts)
+		assert.Equal(t, opts.Burst-result.Remaining, AllowedInWindow(now, bucket, opts))
+		assert.Equal(t, 10-count, AllowedInWindow(now, bucket, opts))
+	}
+
+	bucket := MustGenerate(now, 0, opts)
+	assert.Equal(t, int64(5), AllowedInWindow(now.Add(500*time.Millisecond), bucket, opts))
+	assert.Equal(t, int64(0), AllowedInWindow(now.Add(time.Minute), bucket, opts))
+
+	assert.Equal(t, int64(0), AllowedInWindow(now, bucket, Options{}))
+	assert.Equal(t, int64(0), AllowedInWindow(now, bucket, Options{Burst: Unlimited, Rate: 1, Period: 1}))
+}
+
+func TestAllowedInWindowWarmUp(t *testing.T) {
+	opts := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+		WarmUp: 500 * time.Millisecond,
+	}
+
+	// a new bucket is reported as drained by the warm up consistently
+	assert.Equal(t, int64(5), AllowedInWindow(now, Bucket{}, opts))
+	assert.Equal(t, 500*time.Millisecond, Age(now, Bucket{}, opts))
+	assert.InDelta(t, 5.0, ObservedRate(now, Bucket{}, opts), 0.01)
+}
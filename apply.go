@@ -0,0 +1,77 @@
+package gcra
+
+import "time"
+
+// Apply will compute the bucket that results from count requests of the
+// specified cost at a single point in time without looping. Requests are
+// admitted until the bucket is drained, the remaining requests are limited.
+// The returned result is the result of the last request, except that Consumed
+// reports the total tokens taken from the bucket by all requests. The base cost
+// is charged for every request and requests are never rejected early.
+func Apply(now time.Time, bucket Bucket, count, cost int64, opts Options) (Bucket, Result, error) {
+	// check arguments
+	if count < 0 {
+		return bucket, Result{}, ErrInvalidParameter
+	}
+
+	// ignore early rejections
+	opts.EarlyRejectAt = 0
+
+	// handle queries and unlimited
+	if count == 0 || cost == 0 || opts.Burst == Unlimited {
+		if count == 0 {
+			cost = 0
+		}
+		return Compute(now, bucket, cost, opts)
+	}
+
+	// check cost
+	_, _, err := Compute(now, bucket, cost, opts)
+	if err != nil {
+		return bucket, Result{}, err
+	}
+
+	// query bucket
+	_, result, _ := Compute(now, bucket, 0, opts)
+
+	// determine admitted requests
+	charged := cost + opts.BaseCost
+	admitted := result.Remaining / charged
+	if admitted > count {
+		admitted = count
+	}
+
+	// compute admitted requests (the base cost is added once by Compute)
+	total := func(admitted int64) int64 {
+		if admitted == 0 {
+			return 0
+		}
+		return admitted*charged - opts.BaseCost
+	}
+	newBucket, result, err := Compute(now, bucket, total(admitted), opts)
+	if err != nil {
+		return bucket, Result{}, err
+	}
+
+	// the remaining tokens are rounded and may be one token too many
+	if result.Limited && admitted > 0 {
+		admitted--
+		newBucket, result, err = Compute(now, bucket, total(admitted), opts)
+		if err != nil {
+			return bucket, Result{}, err
+		}
+	}
+
+	// compute last limited request
+	if admitted < count {
+		newBucket, result, err = Compute(now, newBucket, cost, opts)
+		if err != nil {
+			return bucket, Result{}, err
+		}
+	}
+
+	// set consumed
+	result.Consumed = admitted * charged
+
+	return newBucket, result, nil
+}
@@ -0,0 +1,153 @@
+package gcra
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApply(t *testing.T) {
+	opts := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+	}
+
+	bucket, result, err := Apply(now, Bucket{}, 4, 2, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 2,
+		RetryIn:   0,
+		ResetIn:   800 * time.Millisecond,
+		Consumed:  8,
+		Balance:   2,
+	}, result)
+
+	bucket, result, err = Apply(now, bucket, 4, 1, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, Result{
+		Limited:   true,
+		Remaining: 0,
+		RetryIn:   100 * time.Millisecond,
+		ResetIn:   time.Second,
+		Consumed:  2,
+		Balance:   0,
+		Reason:    InsufficientTokens,
+	}, result)
+
+	_, result, err = Apply(now, bucket, 0, 1, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, Result{
+		Limited:   true,
+		Remaining: 0,
+		RetryIn:   0,
+		ResetIn:   time.Second,
+		Balance:   0,
+		Reason:    EmptyQuery,
+	}, result)
+}
+
+func TestApplyLoop(t *testing.T) {
+	opts := Options{
+		Burst:  20,
+		Rate:   3,
+		Period: time.Second,
+	}
+
+	for _, offset := range []time.Duration{0, 1, 500 * time.Millisecond, 2 * time.Second, 4999 * time.Millisecond, 5 * time.Second} {
+		for _, cost := range []int64{1, 2, 3, 7, 20} {
+			for _, count := range []int64{1, 2, 5, 10, 30} {
+				start := Bucket(time.Unix(0, now.Add(offset).UnixNano()))
+
+				bucket := start
+				var result Result
+				var consumed int64
+				for i := int64(0); i < count; i++ {
+					bucket, result = MustCompute(now, bucket, cost, opts)
+					consumed += result.Consumed
+				}
+				result.Consumed = consumed
+
+				newBucket, newResult, err := Apply(now, start, count, cost, opts)
+				assert.NoError(t, err)
+				assert.Equal(t, bucket, newBucket)
+				assert.Equal(t, result, newResult)
+			}
+		}
+	}
+}
+
+func TestApplyBaseCost(t *testing.T) {
+	opts := Options{
+		Burst:    10,
+		Rate:     10,
+		Period:   time.Second,
+		BaseCost: 2,
+	}
+
+	// base cost is charged per request
+	bucket, result, err := Apply(now, Bucket{}, 3, 1, opts)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+	assert.Equal(t, int64(9), result.Consumed)
+	assert.Equal(t, int64(1), result.Remaining)
+	assert.Equal(t, 900*time.Millisecond, time.Time(bucket).Sub(now))
+
+	// equals sequential requests
+	expected := Bucket{}
+	for i := 0; i < 3; i++ {
+		expected, _ = MustCompute(now, expected, 1, opts)
+	}
+	assert.Equal(t, expected, bucket)
+
+	// remaining requests are limited
+	bucket, result, err = Apply(now, Bucket{}, 5, 1, opts)
+	assert.NoError(t, err)
+	assert.True(t, result.Limited)
+	assert.Equal(t, int64(9), result.Consumed)
+	assert.Equal(t, expected, bucket)
+}
+
+func TestApplyEarlyRejectAt(t *testing.T) {
+	opts := Options{
+		Burst:         50,
+		Rate:          10,
+		Period:        time.Second,
+		EarlyRejectAt: 50,
+	}
+
+	// requests are never rejected early
+	for i := 0; i < 100; i++ {
+		at := now.Add(time.Duration(i) * time.Millisecond)
+		bucket, result, err := Apply(at, MustGenerate(at, 30, opts), 20, 1, opts)
+		assert.NoError(t, err)
+		assert.False(t, result.Limited)
+		assert.Equal(t, int64(20), result.Consumed)
+		assert.Equal(t, 4*time.Second, time.Time(bucket).Sub(at))
+	}
+}
+
+func TestApplyUnlimited(t *testing.T) {
+	bucket, result, err := Apply(now, Bucket{}, 1000, 1000, Options{
+		Burst:  Unlimited,
+		Rate:   1,
+		Period: time.Second,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, Bucket{}, bucket)
+	assert.Equal(t, Result{
+		Remaining: math.MaxInt64,
+		Balance:   math.MaxInt64,
+	}, result)
+}
+
+func TestApplyErrors(t *testing.T) {
+	_, _, err := Apply(now, Bucket{}, -1, 1, Options{Burst: 1, Rate: 1, Period: 1})
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, _, err = Apply(now, Bucket{}, 1, 2, Options{Burst: 1, Rate: 1, Period: 1})
+	assert.Equal(t, ErrCostHigherThanBurst, err)
+}
@@ -0,0 +1,37 @@
+package gcra
+
+import "time"
+
+// AuditRecord is a self-describing record of a rate limit decision. The record
+// may be encoded as JSON using snake case names. All durations of the record,
+// including those of the options and result, are rendered as float seconds and
+// times as RFC 3339 timestamps.
+type AuditRecord struct {
+	Now          time.Time `json:"now"`
+	Key          string    `json:"key"`
+	Cost         int64     `json:"cost"`
+	Options      Options   `json:"options"`
+	Result       Result    `json:"result"`
+	BucketBefore Bucket    `json:"bucket_before"`
+	BucketAfter  Bucket    `json:"bucket_after"`
+}
+
+// Audit will perform the GCRA like Compute and additionally return a record
+// of the inputs and outputs of the computation for the specified key.
+func Audit(now time.Time, key string, bucket Bucket, cost int64, opts Options) (Bucket, AuditRecord, error) {
+	// compute
+	newBucket, result, err := Compute(now, bucket, cost, opts)
+	if err != nil {
+		return bucket, AuditRecord{}, err
+	}
+
+	return newBucket, AuditRecord{
+		Now:          now,
+		Key:          key,
+		Cost:         cost,
+		Options:      opts,
+		Result:       result,
+		BucketBefore: bucket,
+		BucketAfter:  newBucket,
+	}, nil
+}
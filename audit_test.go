@@ -0,0 +1,85 @@
+package gcra
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudit(t *testing.T) {
+	opts := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+	}
+
+	bucket := MustGenerate(now, 5, opts)
+	newBucket, record, err := Audit(now, "foo", bucket, 3, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, AuditRecord{
+		Now:     now,
+		Key:     "foo",
+		Cost:    3,
+		Options: opts,
+		Result: Result{
+			Remaining: 2,
+			ResetIn:   800 * time.Millisecond,
+			Consumed:  3,
+			Balance:   2,
+		},
+		BucketBefore: bucket,
+		BucketAfter:  newBucket,
+	}, record)
+
+	expected, _ := MustCompute(now, bucket, 3, opts)
+	assert.Equal(t, expected, newBucket)
+
+	_, _, err = Audit(now, "foo", bucket, 11, opts)
+	assert.Equal(t, ErrCostHigherThanBurst, err)
+}
+
+func TestAuditRecordJSON(t *testing.T) {
+	opts := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+	}
+
+	bucket := MustGenerate(now, 5, opts)
+	_, record, err := Audit(now, "foo", bucket, 3, opts)
+	assert.NoError(t, err)
+
+	buf, err := json.Marshal(record)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"now": "2022-01-23T10:52:00Z",
+		"key": "foo",
+		"cost": 3,
+		"options": {
+			"burst": 10,
+			"rate": 10,
+			"period": 1
+		},
+		"result": {
+			"limited": false,
+			"remaining": 2,
+			"retry_in": 0,
+			"reset_in": 0.8,
+			"consumed": 3,
+			"balance": 2,
+			"reason": "NotLimited"
+		},
+		"bucket_before": "2022-01-23T10:52:00.5Z",
+		"bucket_after": "2022-01-23T10:52:00.8Z"
+	}`, string(buf))
+
+	// times are decoded in UTC
+	var decoded AuditRecord
+	err = json.Unmarshal(buf, &decoded)
+	assert.NoError(t, err)
+	record.BucketBefore = Bucket(time.Time(record.BucketBefore).UTC())
+	record.BucketAfter = Bucket(time.Time(record.BucketAfter).UTC())
+	assert.Equal(t, record, decoded)
+}
@@ -0,0 +1,28 @@
+package gcra
+
+import "time"
+
+// ComputeBorrow will perform the GCRA like Compute but allow a request with a
+// positive priority to borrow up to priority tokens from future regeneration.
+// The TAT may advance beyond the window by up to priority emission intervals,
+// which delays subsequent requests until the borrowed tokens have been
+// regenerated. The borrowed tokens are reported as a negative balance. The
+// priority is capped at the burst, so that the TAT stays within the horizon
+// tolerated by RejectBackwardsTime. A request with a priority of zero or less
+// is computed like Compute.
+//
+// Note: ComputePriority is unrelated and uses the tokens kept in reserve.
+func ComputeBorrow(now time.Time, bucket Bucket, cost int64, priority int, opts Options) (Bucket, Result, error) {
+	// check priority
+	if priority <= 0 {
+		return Compute(now, bucket, cost, opts)
+	}
+
+	// cap borrow
+	borrow := int64(priority)
+	if opts.Burst != Unlimited && borrow > opts.Burst {
+		borrow = opts.Burst
+	}
+
+	return compute(now, bucket, cost, opts, false, borrow)
+}
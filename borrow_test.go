@@ -0,0 +1,101 @@
+package gcra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeBorrow(t *testing.T) {
+	opts := Options{
+		Burst:  2,
+		Rate:   1,
+		Period: time.Second,
+	}
+
+	// drain bucket
+	bucket, result := MustCompute(now, Bucket{}, 2, opts)
+	assert.False(t, result.Limited)
+
+	// normal request is limited
+	_, result, err := ComputeBorrow(now, bucket, 1, 0, opts)
+	assert.NoError(t, err)
+	assert.True(t, result.Limited)
+
+	// priority request borrows
+	bucket, result, err = ComputeBorrow(now, bucket, 1, 2, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, Result{
+		Remaining: 0,
+		ResetIn:   3 * time.Second,
+		Consumed:  1,
+		Balance:   -1,
+	}, result)
+
+	// following normal request is delayed until the borrowed token is repaid
+	_, result = MustCompute(now, bucket, 1, opts)
+	assert.True(t, result.Limited)
+	assert.Equal(t, 2*time.Second, result.RetryIn)
+	assert.Equal(t, 3*time.Second, result.ResetIn)
+
+	_, result = MustCompute(now.Add(2*time.Second), bucket, 1, opts)
+	assert.False(t, result.Limited)
+
+	// borrowing is bounded
+	bucket, result, err = ComputeBorrow(now, bucket, 1, 2, opts)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+	_, result, err = ComputeBorrow(now, bucket, 1, 2, opts)
+	assert.NoError(t, err)
+	assert.True(t, result.Limited)
+
+	_, _, err = ComputeBorrow(now, bucket, 5, 2, opts)
+	assert.Equal(t, ErrCostHigherThanBurst, err)
+}
+
+func TestComputeBorrowCapped(t *testing.T) {
+	opts := Options{
+		Burst:  2,
+		Rate:   1,
+		Period: time.Second,
+	}
+
+	// drain bucket
+	bucket, result := MustCompute(now, Bucket{}, 2, opts)
+	assert.False(t, result.Limited)
+
+	// borrow is capped at the burst
+	bucket, result, err := ComputeBorrow(now, bucket, 2, 10, opts)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+	assert.Equal(t, int64(-2), result.Balance)
+	_, result, err = ComputeBorrow(now, bucket, 1, 10, opts)
+	assert.NoError(t, err)
+	assert.True(t, result.Limited)
+}
+
+func TestComputeBorrowRejectBackwardsTime(t *testing.T) {
+	opts := Options{
+		Burst:               2,
+		Rate:                1,
+		Period:              time.Second,
+		RejectBackwardsTime: true,
+	}
+
+	// drain bucket
+	bucket, result := MustCompute(now, Bucket{}, 2, opts)
+	assert.False(t, result.Limited)
+
+	// borrow full burst
+	bucket, result, err := ComputeBorrow(now, bucket, 2, 2, opts)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+	assert.Equal(t, 4*time.Second, time.Time(bucket).Sub(now))
+
+	// following request at the same instant is not rejected
+	_, result, err = Compute(now, bucket, 1, opts)
+	assert.NoError(t, err)
+	assert.True(t, result.Limited)
+	assert.Equal(t, 3*time.Second, result.RetryIn)
+}
@@ -0,0 +1,24 @@
+package gcra
+
+import "time"
+
+// ComputeBytes will perform the GCRA for the specified amount of bytes. The
+// bytes are converted to tokens using the configured token size, rounding up
+// so that partial tokens are charged as a full token.
+func ComputeBytes(now time.Time, bucket Bucket, bytes int64, opts Options) (Bucket, Result, error) {
+	// check arguments
+	if bytes < 0 || opts.TokenBytes < 0 {
+		return bucket, Result{}, ErrInvalidParameter
+	}
+
+	// convert bytes to tokens
+	cost := bytes
+	if opts.TokenBytes > 0 {
+		cost = bytes / opts.TokenBytes
+		if bytes%opts.TokenBytes > 0 {
+			cost++
+		}
+	}
+
+	return Compute(now, bucket, cost, opts)
+}
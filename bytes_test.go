@@ -0,0 +1,58 @@
+package gcra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeBytes(t *testing.T) {
+	opts := Options{
+		Burst:      10,
+		Rate:       10,
+		Period:     time.Second,
+		TokenBytes: 1024,
+	}
+
+	bucket, result, err := ComputeBytes(now, Bucket{}, 1024, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9), result.Remaining)
+
+	bucket, result, err = ComputeBytes(now, bucket, 1025, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), result.Remaining)
+
+	bucket, result, err = ComputeBytes(now, bucket, 1, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), result.Remaining)
+
+	_, result, err = ComputeBytes(now, bucket, 0, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), result.Remaining)
+
+	_, result, err = ComputeBytes(now, bucket, 7*1024, opts)
+	assert.NoError(t, err)
+	assert.True(t, result.Limited)
+
+	_, _, err = ComputeBytes(now, bucket, 10*1024+1, opts)
+	assert.Equal(t, ErrCostHigherThanBurst, err)
+
+	_, _, err = ComputeBytes(now, bucket, -1, opts)
+	assert.Equal(t, ErrInvalidParameter, err)
+}
+
+func TestComputeBytesUnset(t *testing.T) {
+	opts := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+	}
+
+	_, result, err := ComputeBytes(now, Bucket{}, 3, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), result.Remaining)
+
+	_, _, err = ComputeBytes(now, Bucket{}, 1, Options{Burst: 1, Rate: 1, Period: 1, TokenBytes: -1})
+	assert.Equal(t, ErrInvalidParameter, err)
+}
@@ -0,0 +1,89 @@
+package gcra
+
+import "time"
+
+// CalendarLimiter limits requests like Compute within calendar periods and
+// fully resets the bucket at each period boundary. The periods start at the
+// anchor and have the length specified in years, months and days as used by
+// time.Time.AddDate. The location of the anchor determines the boundaries,
+// e.g. an anchor at midnight UTC with Days set to one resets at every midnight
+// UTC. The zero anchor is a midnight UTC at the start of a year.
+type CalendarLimiter struct {
+	Options Options
+	Anchor  time.Time
+	Years   int
+	Months  int
+	Days    int
+}
+
+// CalendarBucket represents a bucket of a CalendarLimiter. It holds the start
+// of the period the bucket has last been computed in.
+type CalendarBucket struct {
+	Bucket Bucket
+	Start  time.Time
+}
+
+// Period returns the start and end of the period that contains the provided
+// time.
+func (l CalendarLimiter) Period(now time.Time) (time.Time, time.Time, error) {
+	// check period
+	if l.Years < 0 || l.Months < 0 || l.Days < 0 || l.Years == 0 && l.Months == 0 && l.Days == 0 {
+		return time.Time{}, time.Time{}, ErrInvalidParameter
+	}
+
+	// estimate number of periods since the anchor using the average Gregorian
+	// year and month in seconds, which unlike durations do not saturate for
+	// distant anchors like the zero time
+	approx := float64(l.Years)*31556952 + float64(l.Months)*2629746 + float64(l.Days)*86400
+	n := int(float64(now.Unix()-l.Anchor.Unix()) / approx)
+
+	// adjust estimate
+	for l.boundary(n).After(now) {
+		n--
+	}
+	for !l.boundary(n + 1).After(now) {
+		n++
+	}
+
+	return l.boundary(n), l.boundary(n + 1), nil
+}
+
+// Compute will perform the GCRA like Compute using the bucket. If the bucket
+// has last been computed in a previous period, it is reset before. The RetryIn
+// and ResetIn durations are capped at the end of the current period.
+func (l CalendarLimiter) Compute(now time.Time, bucket CalendarBucket, cost int64) (CalendarBucket, Result, error) {
+	// get period
+	start, end, err := l.Period(now)
+	if err != nil {
+		return bucket, Result{}, err
+	}
+
+	// reset bucket if from another period
+	tat := bucket.Bucket
+	if !bucket.Start.Equal(start) {
+		tat = Bucket{}
+	}
+
+	// compute GCRA
+	tat, result, err := Compute(now, tat, cost, l.Options)
+	if err != nil {
+		return bucket, Result{}, err
+	}
+
+	// cap durations at the end of the period
+	if remaining := end.Sub(now); result.RetryIn > remaining {
+		result.RetryIn = remaining
+	}
+	if remaining := end.Sub(now); result.ResetIn > remaining {
+		result.ResetIn = remaining
+	}
+
+	return CalendarBucket{
+		Bucket: tat,
+		Start:  start,
+	}, result, nil
+}
+
+func (l CalendarLimiter) boundary(n int) time.Time {
+	return l.Anchor.AddDate(n*l.Years, n*l.Months, n*l.Days)
+}
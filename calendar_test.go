@@ -0,0 +1,147 @@
+package gcra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendarLimiter(t *testing.T) {
+	l := CalendarLimiter{
+		Options: Options{
+			Burst:  1000,
+			Rate:   1000,
+			Period: 24 * time.Hour,
+		},
+		Anchor: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		Days:   1,
+	}
+
+	// drain bucket before midnight
+	evening := time.Date(2022, 1, 23, 23, 0, 0, 0, time.UTC)
+	bucket, result, err := l.Compute(evening, CalendarBucket{}, 1000)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+	assert.Equal(t, int64(0), result.Remaining)
+	assert.Equal(t, time.Hour, result.ResetIn)
+	assert.Equal(t, time.Date(2022, 1, 23, 0, 0, 0, 0, time.UTC), bucket.Start)
+
+	// durations are capped at the boundary
+	_, result, err = l.Compute(evening.Add(59*time.Minute), bucket, 100)
+	assert.NoError(t, err)
+	assert.True(t, result.Limited)
+	assert.Equal(t, time.Minute, result.RetryIn)
+	assert.Equal(t, time.Minute, result.ResetIn)
+
+	// bucket is fully reset at midnight
+	midnight := time.Date(2022, 1, 24, 0, 0, 0, 0, time.UTC)
+	bucket, result, err = l.Compute(midnight, bucket, 0)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+	assert.Equal(t, int64(1000), result.Remaining)
+	assert.Equal(t, midnight, bucket.Start)
+
+	// smoothing within the period
+	bucket, result, err = l.Compute(midnight, bucket, 1000)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+	_, result, err = l.Compute(midnight.Add(time.Hour), bucket, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), result.Remaining)
+}
+
+func TestCalendarLimiterPeriod(t *testing.T) {
+	zone := time.FixedZone("UTC+2", 2*60*60)
+
+	l := CalendarLimiter{
+		Anchor: time.Date(2022, 1, 1, 0, 0, 0, 0, zone),
+		Days:   1,
+	}
+
+	start, end, err := l.Period(time.Date(2022, 1, 23, 23, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2022, 1, 24, 0, 0, 0, 0, zone), start)
+	assert.Equal(t, time.Date(2022, 1, 25, 0, 0, 0, 0, zone), end)
+
+	l = CalendarLimiter{
+		Anchor: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		Months: 1,
+	}
+
+	for _, item := range []struct {
+		now, start, end time.Time
+	}{
+		{
+			now:   time.Date(2022, 3, 15, 12, 0, 0, 0, time.UTC),
+			start: time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC),
+			end:   time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			now:   time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC),
+			start: time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC),
+			end:   time.Date(2022, 4, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			now:   time.Date(2022, 2, 28, 23, 59, 59, 0, time.UTC),
+			start: time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC),
+			end:   time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			now:   time.Date(2030, 7, 4, 0, 0, 0, 0, time.UTC),
+			start: time.Date(2030, 7, 1, 0, 0, 0, 0, time.UTC),
+			end:   time.Date(2030, 8, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			now:   time.Date(2021, 12, 4, 0, 0, 0, 0, time.UTC),
+			start: time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC),
+			end:   time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	} {
+		start, end, err := l.Period(item.now)
+		assert.NoError(t, err)
+		assert.Equal(t, item.start, start, item.now)
+		assert.Equal(t, item.end, end, item.now)
+	}
+}
+
+func TestCalendarLimiterZeroAnchor(t *testing.T) {
+	for _, item := range []struct {
+		limiter    CalendarLimiter
+		start, end time.Time
+	}{
+		{
+			limiter: CalendarLimiter{Days: 1},
+			start:   time.Date(2022, 1, 23, 0, 0, 0, 0, time.UTC),
+			end:     time.Date(2022, 1, 24, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			limiter: CalendarLimiter{Months: 1},
+			start:   time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			end:     time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			limiter: CalendarLimiter{Years: 1},
+			start:   time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			end:     time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	} {
+		start, end, err := item.limiter.Period(now)
+		assert.NoError(t, err)
+		assert.Equal(t, item.start, start)
+		assert.Equal(t, item.end, end)
+	}
+}
+
+func TestCalendarLimiterErrors(t *testing.T) {
+	_, _, err := CalendarLimiter{}.Period(now)
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, _, err = CalendarLimiter{Days: -1}.Period(now)
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	bucket := CalendarBucket{Bucket: Bucket(now)}
+	newBucket, _, err := CalendarLimiter{Days: 1}.Compute(now, bucket, 1)
+	assert.Equal(t, ErrInvalidParameter, err)
+	assert.Equal(t, bucket, newBucket)
+}
@@ -0,0 +1,54 @@
+package gcra
+
+import "time"
+
+// ToCellThrottleArgs returns the command arguments for the CL.THROTTLE command
+// of the redis-cell module that correspond to the provided options and cost.
+// In redis-cell the total limit is the max burst plus one, so the burst is
+// reduced by one. As redis-cell only supports whole seconds, the rate and
+// period are scaled to the smallest whole number of seconds, e.g. a rate of 10
+// per 500ms yields a rate of 20 per second.
+func ToCellThrottleArgs(key string, opts Options, cost int64) []interface{} {
+	// scale rate and period to whole seconds
+	scale := int64(time.Second) / gcd(int64(opts.Period), int64(time.Second))
+	rate := mulAdd(opts.Rate, scale, 0)
+	period := mulAdd(int64(opts.Period), scale, 0) / int64(time.Second)
+
+	return []interface{}{
+		"CL.THROTTLE",
+		key,
+		opts.Burst - 1,
+		rate,
+		period,
+		cost,
+	}
+}
+
+// ResultFromCellThrottle returns a result from a response to the CL.THROTTLE
+// command of the redis-cell module. The response consists of the limited flag,
+// the total limit, the remaining tokens and the retry and reset durations in
+// seconds. A retry duration of -1 means the request has been allowed. Consumed
+// is not reported by redis-cell and left zero. Malformed responses yield a
+// zero result.
+func ResultFromCellThrottle(resp []int64) Result {
+	// check response
+	if len(resp) != 5 {
+		return Result{}
+	}
+
+	// prepare result
+	result := Result{
+		Limited:   resp[0] == 1,
+		Remaining: resp[2],
+		ResetIn:   time.Duration(resp[4]) * time.Second,
+		Balance:   resp[2],
+	}
+	if resp[3] > 0 {
+		result.RetryIn = time.Duration(resp[3]) * time.Second
+	}
+	if result.Limited {
+		result.Reason = InsufficientTokens
+	}
+
+	return result
+}
@@ -0,0 +1,56 @@
+package gcra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToCellThrottleArgs(t *testing.T) {
+	// CL.THROTTLE user123 15 30 60 1
+	args := ToCellThrottleArgs("user123", Options{
+		Burst:  16,
+		Rate:   30,
+		Period: time.Minute,
+	}, 1)
+	assert.Equal(t, []interface{}{"CL.THROTTLE", "user123", int64(15), int64(30), int64(60), int64(1)}, args)
+
+	// sub-second periods are scaled to whole seconds
+	args = ToCellThrottleArgs("user123", Options{
+		Burst:  10,
+		Rate:   10,
+		Period: 500 * time.Millisecond,
+	}, 1)
+	assert.Equal(t, []interface{}{"CL.THROTTLE", "user123", int64(9), int64(20), int64(1), int64(1)}, args)
+
+	// fractional periods are scaled to whole seconds
+	args = ToCellThrottleArgs("user123", Options{
+		Burst:  10,
+		Rate:   3,
+		Period: 1500 * time.Millisecond,
+	}, 1)
+	assert.Equal(t, []interface{}{"CL.THROTTLE", "user123", int64(9), int64(6), int64(3), int64(1)}, args)
+}
+
+func TestResultFromCellThrottle(t *testing.T) {
+	// documented response of an allowed request
+	result := ResultFromCellThrottle([]int64{0, 16, 15, -1, 2})
+	assert.Equal(t, Result{
+		Remaining: 15,
+		ResetIn:   2 * time.Second,
+		Balance:   15,
+	}, result)
+
+	// limited request
+	result = ResultFromCellThrottle([]int64{1, 16, 0, 2, 32})
+	assert.Equal(t, Result{
+		Limited: true,
+		RetryIn: 2 * time.Second,
+		ResetIn: 32 * time.Second,
+		Reason:  InsufficientTokens,
+	}, result)
+
+	assert.Equal(t, Result{}, ResultFromCellThrottle(nil))
+	assert.Equal(t, Result{}, ResultFromCellThrottle([]int64{0, 1, 2}))
+}
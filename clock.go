@@ -0,0 +1,40 @@
+package gcra
+
+import "time"
+
+// MonotonicClock derives the current time from a wall clock anchor and the
+// monotonic time elapsed since the anchor was taken. Unlike time.Now, the
+// returned times are not affected by steps of the wall clock (e.g. NTP
+// adjustments) after the clock has been created and can be passed to Compute
+// to prevent buckets from instantly refilling or draining. It is safe for
+// concurrent use.
+type MonotonicClock struct {
+	anchor  time.Time
+	elapsed func() time.Duration
+}
+
+// NewMonotonicClock creates and returns a new monotonic clock anchored at the
+// current time.
+func NewMonotonicClock() *MonotonicClock {
+	anchor := time.Now()
+	return newMonotonicClock(anchor, func() time.Duration {
+		return time.Since(anchor)
+	})
+}
+
+func newMonotonicClock(anchor time.Time, elapsed func() time.Duration) *MonotonicClock {
+	return &MonotonicClock{
+		anchor:  anchor.Round(0),
+		elapsed: elapsed,
+	}
+}
+
+// Anchor returns the wall clock time the clock has been anchored at.
+func (c *MonotonicClock) Anchor() time.Time {
+	return c.anchor
+}
+
+// Now returns the anchor advanced by the monotonic time elapsed since then.
+func (c *MonotonicClock) Now() time.Time {
+	return c.anchor.Add(c.elapsed())
+}
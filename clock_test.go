@@ -0,0 +1,77 @@
+package gcra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonotonicClock(t *testing.T) {
+	clock := NewMonotonicClock()
+	assert.False(t, clock.Anchor().IsZero())
+
+	a := clock.Now()
+	b := clock.Now()
+	assert.False(t, b.Before(a))
+	assert.False(t, a.Before(clock.Anchor()))
+}
+
+func TestMonotonicClockWallJump(t *testing.T) {
+	opts := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+	}
+
+	// the wall clock is stepped by the offset while the monotonic time
+	// elapsed advances regularly
+	var elapsed, offset time.Duration
+	wall := func() time.Time {
+		return now.Add(elapsed + offset)
+	}
+	clock := newMonotonicClock(now, func() time.Duration {
+		return elapsed
+	})
+
+	// drain buckets
+	wallBucket, result, err := Compute(wall(), Bucket{}, 10, opts)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+	clockBucket, result, err := Compute(clock.Now(), Bucket{}, 10, opts)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+
+	// step wall clock forward
+	elapsed = 100 * time.Millisecond
+	offset = time.Hour
+
+	// wall clock bucket is instantly refilled
+	_, result, err = Compute(wall(), wallBucket, 10, opts)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+
+	// clock bucket only refilled a single token
+	_, result, err = Compute(clock.Now(), clockBucket, 10, opts)
+	assert.NoError(t, err)
+	assert.True(t, result.Limited)
+	clockBucket, result, err = Compute(clock.Now(), clockBucket, 1, opts)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+	assert.Equal(t, int64(0), result.Remaining)
+
+	// step wall clock backward
+	elapsed = 200 * time.Millisecond
+	offset = -time.Hour
+
+	// wall clock bucket over drains
+	_, result, err = Compute(wall(), wallBucket, 1, opts)
+	assert.NoError(t, err)
+	assert.True(t, result.Limited)
+	assert.True(t, result.RetryIn > 59*time.Minute)
+
+	// clock bucket again refilled a single token
+	_, result, err = Compute(clock.Now(), clockBucket, 1, opts)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+}
@@ -0,0 +1,22 @@
+package gcra
+
+import "time"
+
+// ComputeDelta will perform the GCRA like Compute but return the change of the
+// encoded bucket instead of the new bucket. The delta may be applied to a
+// bucket stored using Encode with an atomic increment. It is zero if the
+// request has been limited and the increment of the request otherwise. If the
+// TAT has been reset to now, the delta also includes the duration between the
+// old TAT and now, so that the increment alone is not sufficient.
+//
+// Note: The delta is only correct if the stored bucket has not been changed
+// since it has been read.
+func ComputeDelta(now time.Time, bucket Bucket, cost int64, opts Options) (int64, Result, error) {
+	// compute
+	newBucket, result, err := Compute(now, bucket, cost, opts)
+	if err != nil {
+		return 0, result, err
+	}
+
+	return newBucket.Encode() - bucket.Encode(), result, nil
+}
@@ -0,0 +1,52 @@
+package gcra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeDelta(t *testing.T) {
+	opts := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+	}
+
+	// without reset
+	bucket := MustGenerate(now, 5, opts)
+	delta, result, err := ComputeDelta(now, bucket, 2, opts)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+	assert.Equal(t, int64(200*time.Millisecond), delta)
+
+	newBucket, _ := MustCompute(now, bucket, 2, opts)
+	assert.Equal(t, newBucket, Decode(bucket.Encode()+delta))
+
+	// with reset
+	bucket = MustGenerate(now, 10, opts)
+	delta, result, err = ComputeDelta(now.Add(time.Second), bucket, 2, opts)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+	assert.Equal(t, int64(1200*time.Millisecond), delta)
+
+	newBucket, _ = MustCompute(now.Add(time.Second), bucket, 2, opts)
+	assert.Equal(t, newBucket, Decode(bucket.Encode()+delta))
+
+	// zero bucket
+	delta, _, err = ComputeDelta(now, Bucket{}, 1, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(100*time.Millisecond).UnixNano(), delta)
+
+	// limited
+	bucket = MustGenerate(now, 0, opts)
+	delta, result, err = ComputeDelta(now, bucket, 1, opts)
+	assert.NoError(t, err)
+	assert.True(t, result.Limited)
+	assert.Equal(t, int64(0), delta)
+
+	// error
+	_, _, err = ComputeDelta(now, bucket, 11, opts)
+	assert.Equal(t, ErrCostHigherThanBurst, err)
+}
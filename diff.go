@@ -0,0 +1,28 @@
+package gcra
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Diff returns a human-readable description of the fields that differ between
+// the result and the other result, e.g. "RetryIn: 1s != 1.000000001s". It
+// returns an empty string if the results are equal.
+func (r Result) Diff(other Result) string {
+	// collect differences
+	var diffs []string
+	add := func(name string, a, b interface{}) {
+		if a != b {
+			diffs = append(diffs, fmt.Sprintf("%s: %v != %v", name, a, b))
+		}
+	}
+	add("Limited", r.Limited, other.Limited)
+	add("Remaining", r.Remaining, other.Remaining)
+	add("RetryIn", r.RetryIn, other.RetryIn)
+	add("ResetIn", r.ResetIn, other.ResetIn)
+	add("Consumed", r.Consumed, other.Consumed)
+	add("Balance", r.Balance, other.Balance)
+	add("Reason", r.Reason, other.Reason)
+
+	return strings.Join(diffs, ", ")
+}
@@ -0,0 +1,33 @@
+package gcra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultDiff(t *testing.T) {
+	result := Result{
+		Remaining: 2,
+		ResetIn:   time.Second,
+		Consumed:  1,
+		Balance:   2,
+	}
+	assert.Equal(t, "", result.Diff(result))
+	assert.Equal(t, "", Result{}.Diff(Result{}))
+
+	other := result
+	other.ResetIn += time.Nanosecond
+	assert.Equal(t, "ResetIn: 1s != 1.000000001s", result.Diff(other))
+
+	other = Result{
+		Limited:   true,
+		Remaining: 2,
+		RetryIn:   500 * time.Millisecond,
+		ResetIn:   time.Second,
+		Balance:   2,
+		Reason:    InsufficientTokens,
+	}
+	assert.Equal(t, "Limited: false != true, RetryIn: 0s != 500ms, Consumed: 1 != 0, Reason: NotLimited != InsufficientTokens", result.Diff(other))
+}
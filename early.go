@@ -0,0 +1,27 @@
+package gcra
+
+// earlyReject returns whether a request with the specified available tokens
+// should be rejected early. The probability of a rejection grows linearly from
+// zero at the threshold to one at zero available tokens.
+func earlyReject(now, tat, cost, available, threshold int64) bool {
+	// check threshold
+	if threshold <= 0 || available >= threshold {
+		return false
+	} else if available <= 0 {
+		return true
+	}
+
+	// derive a deterministic sample in [0, 1) from the request
+	sample := float64(mix(uint64(now)^mix(uint64(tat)^mix(uint64(cost)))) >> 11)
+	sample /= 1 << 53
+
+	return sample < float64(threshold-available)/float64(threshold)
+}
+
+// mix is the splitmix64 finalizer.
+func mix(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
@@ -0,0 +1,78 @@
+package gcra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEarlyRejectAt(t *testing.T) {
+	opts := Options{
+		Burst:         10,
+		Rate:          10,
+		Period:        time.Second,
+		EarlyRejectAt: 5,
+	}
+
+	last := -1
+	for available := int64(10); available >= 1; available-- {
+		var rejected int
+		for i := 0; i < 1000; i++ {
+			at := now.Add(time.Duration(i) * time.Microsecond)
+			bucket := MustGenerate(at, available, opts)
+
+			newBucket, result := MustCompute(at, bucket, 1, opts)
+			if result.Limited {
+				rejected++
+				assert.Equal(t, bucket, newBucket)
+				assert.Equal(t, EarlyRejection, result.Reason)
+				assert.Equal(t, available, result.Remaining)
+				assert.Equal(t, int64(0), result.Consumed)
+				assert.Equal(t, time.Duration(5-available)*100*time.Millisecond, result.RetryIn)
+			}
+		}
+
+		if available >= 5 {
+			assert.Equal(t, 0, rejected, "available=%d", available)
+		}
+		assert.True(t, rejected >= last, "available=%d", available)
+		last = rejected
+	}
+	assert.True(t, last > 700)
+
+	// decisions are deterministic
+	bucket := MustGenerate(now, 2, opts)
+	_, result1 := MustCompute(now, bucket, 1, opts)
+	_, result2 := MustCompute(now, bucket, 1, opts)
+	assert.Equal(t, result1, result2)
+
+	// queries are never rejected early
+	_, result := MustCompute(now, MustGenerate(now, 1, opts), 0, opts)
+	assert.False(t, result.Limited)
+
+	// empty buckets are limited as usual
+	_, result = MustCompute(now, MustGenerate(now, 0, opts), 1, opts)
+	assert.True(t, result.Limited)
+	assert.Equal(t, InsufficientTokens, result.Reason)
+}
+
+func TestEarlyReject(t *testing.T) {
+	assert.False(t, earlyReject(1, 2, 1, 5, 5))
+	assert.False(t, earlyReject(1, 2, 1, 5, 0))
+	assert.False(t, earlyReject(1, 2, 1, -1, 0))
+	assert.True(t, earlyReject(1, 2, 1, 0, 5))
+}
+
+func TestEarlyRejectAtInvalid(t *testing.T) {
+	_, _, err := Compute(now, Bucket{}, 1, Options{Burst: 1, Rate: 1, Period: 1, EarlyRejectAt: -1})
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	// remaining tokens can never reach the threshold
+	_, _, err = Compute(now, Bucket{}, 1, Options{Burst: 10, Rate: 1, Period: 1, EarlyRejectAt: 11})
+	assert.Equal(t, ErrInvalidParameter, err)
+	_, _, err = Compute(now, Bucket{}, 1, Options{Burst: 10, Rate: 1, Period: 1, Reserve: 2, EarlyRejectAt: 9})
+	assert.Equal(t, ErrInvalidParameter, err)
+	_, _, err = Compute(now, Bucket{}, 1, Options{Burst: 10, Rate: 1, Period: 1, Reserve: 2, EarlyRejectAt: 8})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,47 @@
+package gcra
+
+import (
+	"math"
+	"time"
+)
+
+// EffectiveBurst returns the largest cost a single request may use from the
+// bucket at the provided time, i.e. the largest cost that is admitted by
+// Compute. It accounts for all options that affect the admissible cost like
+// the reserve, the exclusive burst, the overdraft, the base cost and the warm
+// up of new buckets. Early rejections are ignored. Unlimited options yield
+// math.MaxInt64 and invalid options yield zero.
+func EffectiveBurst(now time.Time, bucket Bucket, opts Options) int64 {
+	// handle unlimited
+	if opts.Burst == Unlimited {
+		return math.MaxInt64
+	}
+
+	// ignore early rejections
+	opts.EarlyRejectAt = 0
+
+	// check options
+	if opts.check() != nil {
+		return 0
+	}
+
+	// determine upper bound
+	hi := opts.burst(false) + opts.AllowOverdraft - opts.BaseCost
+	if hi <= 0 {
+		return 0
+	}
+
+	// search largest admitted cost
+	var lo int64
+	for lo < hi {
+		cost := hi - (hi-lo)/2
+		_, result, err := Compute(now, bucket, cost, opts)
+		if err == nil && !result.Limited {
+			lo = cost
+		} else {
+			hi = cost - 1
+		}
+	}
+
+	return lo
+}
@@ -0,0 +1,67 @@
+package gcra
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEffectiveBurst(t *testing.T) {
+	base := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+	}
+
+	for i, item := range []struct {
+		modify func(*Options)
+		bucket Bucket
+		burst  int64
+	}{
+		{modify: func(*Options) {}, burst: 10},
+		{modify: func(*Options) {}, bucket: MustGenerate(now, 4, base), burst: 4},
+		{modify: func(*Options) {}, bucket: MustGenerate(now, 0, base), burst: 0},
+		{modify: func(o *Options) { o.Reserve = 2 }, burst: 8},
+		{modify: func(o *Options) { o.Exclusive = true }, burst: 9},
+		{modify: func(o *Options) { o.BaseCost = 3 }, burst: 7},
+		{modify: func(o *Options) { o.AllowOverdraft = 5 }, burst: 15},
+		{modify: func(o *Options) { o.WarmUp = 600 * time.Millisecond }, burst: 4},
+		{modify: func(o *Options) { o.WarmUp = 600 * time.Millisecond }, bucket: MustGenerate(now, 6, base), burst: 6},
+		{modify: func(o *Options) { o.EarlyRejectAt = 10 }, burst: 10},
+		{modify: func(o *Options) {
+			o.Reserve = 2
+			o.BaseCost = 1
+		}, burst: 7},
+		{modify: func(o *Options) {
+			o.Reserve = 2
+			o.BaseCost = 1
+			o.WarmUp = 500 * time.Millisecond
+		}, burst: 2},
+		{modify: func(o *Options) {
+			o.Reserve = 2
+			o.Exclusive = true
+			o.AllowOverdraft = 4
+			o.BaseCost = 1
+		}, bucket: MustGenerate(now, 5, base), burst: 5},
+	} {
+		opts := base
+		item.modify(&opts)
+		assert.Equal(t, item.burst, EffectiveBurst(now, item.bucket, opts), i)
+	}
+}
+
+func TestEffectiveBurstUnlimited(t *testing.T) {
+	assert.Equal(t, int64(math.MaxInt64), EffectiveBurst(now, Bucket{}, Options{
+		Burst:  Unlimited,
+		Rate:   1,
+		Period: time.Second,
+	}))
+}
+
+func TestEffectiveBurstInvalid(t *testing.T) {
+	assert.Equal(t, int64(0), EffectiveBurst(now, Bucket{}, Options{}))
+	assert.Equal(t, int64(0), EffectiveBurst(now, Bucket{}, Options{Burst: 2, Rate: 1, Period: time.Second, Reserve: 2}))
+	assert.Equal(t, int64(0), EffectiveBurst(now, Bucket{}, Options{Burst: 2, Rate: 1, Period: time.Second, Reserve: 1, BaseCost: 2}))
+}
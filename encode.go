@@ -0,0 +1,22 @@
+package gcra
+
+import "time"
+
+// Encode returns the TAT of the bucket as unix nanoseconds. Unlike UnixMilli
+// the encoding is lossless for all TATs between the years 1678 and 2262. A zero
+// bucket yields zero.
+func (b Bucket) Encode() int64 {
+	if time.Time(b).IsZero() {
+		return 0
+	}
+	return time.Time(b).UnixNano()
+}
+
+// Decode returns a bucket with the TAT represented by the provided unix
+// nanoseconds. Zero yields a zero bucket.
+func Decode(v int64) Bucket {
+	if v == 0 {
+		return Bucket{}
+	}
+	return Bucket(time.Unix(0, v))
+}
@@ -0,0 +1,26 @@
+package gcra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucketEncode(t *testing.T) {
+	assert.Equal(t, int64(0), Bucket{}.Encode())
+	assert.Equal(t, Bucket{}, Decode(0))
+
+	opts := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+	}
+
+	bucket := MustGenerate(now, 3, opts)
+	assert.Equal(t, now.Add(700*time.Millisecond).UnixNano(), bucket.Encode())
+	assert.Equal(t, bucket, Decode(bucket.Encode()))
+
+	future := Bucket(time.Unix(0, time.Date(2200, 1, 1, 0, 0, 0, 1, time.UTC).UnixNano()))
+	assert.Equal(t, future, Decode(future.Encode()))
+}
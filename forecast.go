@@ -0,0 +1,29 @@
+//go:build go1.23
+
+package gcra
+
+import (
+	"iter"
+	"time"
+)
+
+// Forecasts returns an iterator that yields the results of zero cost queries
+// of the bucket at the specified amount of steps starting from the provided
+// time. The bucket is not modified. Invalid options yield no results.
+func Forecasts(start time.Time, step time.Duration, steps int, bucket Bucket, opts Options) iter.Seq2[time.Time, Result] {
+	return func(yield func(time.Time, Result) bool) {
+		for i := 0; i < steps; i++ {
+			// compute result
+			now := start.Add(step * time.Duration(i))
+			_, result, err := Compute(now, bucket, 0, opts)
+			if err != nil {
+				return
+			}
+
+			// yield result
+			if !yield(now, result) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+//go:build go1.23
+
+package gcra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForecasts(t *testing.T) {
+	opts := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+	}
+
+	bucket := MustGenerate(now, 0, opts)
+
+	var times []time.Time
+	var remaining []int64
+	for tt, result := range Forecasts(now, 250*time.Millisecond, 6, bucket, opts) {
+		times = append(times, tt)
+		remaining = append(remaining, result.Remaining)
+	}
+	assert.Equal(t, []time.Time{
+		now,
+		now.Add(250 * time.Millisecond),
+		now.Add(500 * time.Millisecond),
+		now.Add(750 * time.Millisecond),
+		now.Add(time.Second),
+		now.Add(1250 * time.Millisecond),
+	}, times)
+	assert.Equal(t, []int64{0, 3, 5, 8, 10, 10}, remaining)
+
+	for i := 1; i < len(remaining); i++ {
+		assert.True(t, remaining[i] >= remaining[i-1])
+	}
+
+	_, result := MustCompute(now, bucket, 0, opts)
+	assert.Equal(t, int64(0), result.Remaining)
+
+	var count int
+	for range Forecasts(now, time.Second, 10, bucket, opts) {
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	assert.Equal(t, 3, count)
+
+	count = 0
+	for range Forecasts(now, time.Second, 10, bucket, Options{}) {
+		count++
+	}
+	assert.Equal(t, 0, count)
+}
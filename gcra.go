@@ -3,7 +3,9 @@ package gcra
 
 import (
 	"errors"
+	"fmt"
 	"math"
+	"strconv"
 	"time"
 )
 
@@ -14,16 +16,420 @@ var ErrInvalidParameter = errors.New("invalid parameter")
 // specified burst.
 var ErrCostHigherThanBurst = errors.New("cost higher than burst")
 
+// ErrZeroInterval is returned if the options yield an emission interval that
+// rounds to zero nanoseconds. Set MinInterval to floor the interval.
+var ErrZeroInterval = errors.New("zero emission interval")
+
+// ErrClockSkew is returned if RejectBackwardsTime is enabled and the provided
+// time is before the last update of the bucket implied by its TAT.
+var ErrClockSkew = errors.New("clock skew")
+
+// ErrOverflow is returned if a computation overflows the representable range
+// of times.
+var ErrOverflow = errors.New("overflow")
+
+// Unlimited may be used as the burst to disable limiting. Computations with
+// unlimited options never limit, report math.MaxInt64 remaining tokens and
+// leave the bucket unchanged.
+const Unlimited int64 = -1
+
 // Bucket represents a GCRA bucket. The value represents the theoretical arrival
 // time (TAT) which encodes the point in time at which the bucket is full again.
 type Bucket time.Time
 
 // Options define the GCRA options. Specify burst as the maximum tokens
-// available and rate as the regeneration of tokens per period.
+// available and rate as the regeneration of tokens per period. Set burst to
+// Unlimited to disable limiting.
 type Options struct {
 	Burst  int64
 	Rate   int64
 	Period time.Duration
+
+	// Exclusive treats the burst as an exclusive bound, so that strictly
+	// fewer than burst tokens are available. A request that consumes the
+	// last available token is still admitted.
+	Exclusive bool
+
+	// MinInterval floors the emission interval to prevent very high rates
+	// from degrading to an emission interval of a few or zero nanoseconds.
+	MinInterval time.Duration
+
+	// TokenBytes specifies the size of a token in bytes as used by
+	// ComputeBytes. If zero, a byte equals a token.
+	TokenBytes int64
+
+	// Resolution specifies the unit used internally to represent time. A
+	// coarser unit like time.Millisecond trades sub-unit precision for a much
+	// larger representable horizon. The resolution must either divide or be a
+	// multiple of a second. If zero, nanoseconds are used.
+	Resolution time.Duration
+
+	// Reserve specifies the amount of tokens that are kept in reserve for
+	// priority requests computed using ComputePriority. Normal requests are
+	// limited once only the reserve is left. The reserve must be less than the
+	// burst.
+	Reserve int64
+
+	// AllowOverdraft specifies the amount of tokens a request may exceed the
+	// remaining tokens. The exceeding tokens are recorded as debt that must be
+	// repaid by regeneration before further requests are admitted.
+	AllowOverdraft int64
+
+	// EarlyRejectAt specifies an amount of remaining tokens below which
+	// requests are rejected with a probability that grows linearly until it
+	// reaches one for an empty bucket. The decision is derived from the
+	// request time and bucket to be deterministic. Zero cost queries are never
+	// rejected early. Rejected requests are advised to retry once the remaining
+	// tokens have regenerated to EarlyRejectAt, which must therefore not exceed
+	// the burst available to regular requests.
+	EarlyRejectAt int64
+
+	// NoReset disables the reset of a TAT in the past to the current time.
+	// The bucket then behaves as a strict virtual scheduler that accumulates
+	// credit while idle: after an idle period, requests are admitted until
+	// the TAT has caught up with the current time, which may be more than the
+	// burst. The reported remaining tokens are still capped by the burst.
+	NoReset bool
+
+	// ClampDurations caps the RetryIn and ResetIn durations of results at the
+	// window. This prevents surfacing absurd durations for future dated
+	// buckets, e.g. due to clock skew or corrupted data.
+	ClampDurations bool
+
+	// RejectBackwardsTime enables the detection of clocks that go backwards,
+	// e.g. due to disagreeing servers. If the provided time is more than the
+	// ClockSkewTolerance before the earliest possible last update of the
+	// bucket, the computation fails with ErrClockSkew. As Reserve and
+	// ComputeBorrow may advance the TAT beyond the window, the last update is
+	// assumed to be up to two windows before the TAT.
+	RejectBackwardsTime bool
+
+	// ClockSkewTolerance specifies the tolerated clock skew used with
+	// RejectBackwardsTime.
+	ClockSkewTolerance time.Duration
+
+	// BaseCost specifies a fixed cost that is added to the cost of every
+	// request computed using Compute or ComputePriority. Zero cost queries
+	// are not charged. The base cost must not exceed the burst.
+	BaseCost int64
+
+	// WarmUp specifies a duration over which new buckets ramp up to the full
+	// burst. A zero bucket is treated as being drained such that it becomes
+	// full after the warm up at the regular rate. The warm up is capped at the
+	// window, in which case a new bucket starts empty.
+	WarmUp time.Duration
+
+	// SustainedRate and SustainedPeriod specify a second, usually slower
+	// rate as used by ComputeSustained. The sustained rate is enforced by a
+	// separate bucket that allows SustainedRate tokens per SustainedPeriod
+	// while the burst may be consumed instantly. If zero, only the regular
+	// rate is enforced.
+	SustainedRate   int64
+	SustainedPeriod time.Duration
+}
+
+// EmissionInterval returns the interval at which tokens are regenerated. The
+// interval is rounded to the nearest nanosecond and floored by MinInterval.
+// All computations only use the rounded interval, so options with equal
+// intervals and bursts yield identical results.
+func (o Options) EmissionInterval() time.Duration {
+	interval := time.Duration(roundDiv(int64(o.Period), o.Rate))
+	if interval < o.MinInterval {
+		interval = o.MinInterval
+	}
+	return interval
+}
+
+// Window returns the duration it takes for an empty bucket to become full
+// again.
+func (o Options) Window() time.Duration {
+	return o.EmissionInterval() * time.Duration(o.burst(false))
+}
+
+// PerSecond returns the sustained rate of tokens regenerated per second.
+func (o Options) PerSecond() float64 {
+	interval := o.EmissionInterval()
+	if interval <= 0 {
+		return 0
+	}
+	return float64(time.Second) / float64(interval)
+}
+
+// RateError returns the relative deviation of the sustained rate from the
+// configured rate that is caused by rounding the emission interval to the
+// resolution. It grows as the emission interval approaches the resolution and
+// may be used to guard against very small periods. A zero interval yields an
+// infinite error.
+func (o Options) RateError() float64 {
+	// check rate
+	if o.Rate <= 0 {
+		return math.Inf(1)
+	}
+
+	// compute exact interval
+	exact := float64(o.Period) / float64(o.Rate)
+	if exact < float64(o.MinInterval) {
+		exact = float64(o.MinInterval)
+	}
+
+	// compute used interval
+	res := o.resolution()
+	interval := roundDiv(int64(o.EmissionInterval()), int64(res)) * int64(res)
+	if interval <= 0 {
+		return math.Inf(1)
+	}
+
+	return math.Abs(exact/float64(interval) - 1)
+}
+
+// Stricter returns the options that yield the lower sustained throughput. The
+// throughput is compared using the emission interval, which normalizes rates
+// across different periods. On ties, the options with the smaller burst are
+// returned. Unlimited options are never stricter than limited options.
+func (o Options) Stricter(other Options) Options {
+	// handle unlimited
+	if o.Burst == Unlimited {
+		return other
+	} else if other.Burst == Unlimited {
+		return o
+	}
+
+	// compare emission intervals
+	a, b := o.EmissionInterval(), other.EmissionInterval()
+	if a > b {
+		return o
+	} else if b > a {
+		return other
+	}
+
+	// compare bursts
+	if other.Burst < o.Burst {
+		return other
+	}
+
+	return o
+}
+
+// String returns a human-readable representation of the options including the
+// computed emission interval, e.g. "100 burst, 10/1s (emit 100ms)".
+func (o Options) String() string {
+	// format burst
+	burst := strconv.FormatInt(o.Burst, 10) + " burst"
+	if o.Burst == Unlimited {
+		burst = "unlimited burst"
+	}
+
+	// format options
+	str := fmt.Sprintf("%s, %d/%s (emit %s)", burst, o.Rate, o.Period, o.EmissionInterval())
+	if o.Exclusive {
+		str += ", exclusive"
+	}
+	if o.MinInterval != 0 {
+		str += fmt.Sprintf(", min %s", o.MinInterval)
+	}
+	if o.TokenBytes != 0 {
+		str += fmt.Sprintf(", %d bytes/token", o.TokenBytes)
+	}
+	if o.Resolution != 0 {
+		str += fmt.Sprintf(", resolution %s", o.Resolution)
+	}
+	if o.Reserve != 0 {
+		str += fmt.Sprintf(", %d reserved", o.Reserve)
+	}
+	if o.AllowOverdraft != 0 {
+		str += fmt.Sprintf(", %d overdraft", o.AllowOverdraft)
+	}
+	if o.EarlyRejectAt != 0 {
+		str += fmt.Sprintf(", early reject at %d", o.EarlyRejectAt)
+	}
+	if o.NoReset {
+		str += ", no reset"
+	}
+	if o.ClampDurations {
+		str += ", clamp durations"
+	}
+	if o.RejectBackwardsTime {
+		str += ", reject backwards time"
+	}
+	if o.ClockSkewTolerance != 0 {
+		str += fmt.Sprintf(", skew tolerance %s", o.ClockSkewTolerance)
+	}
+	if o.BaseCost != 0 {
+		str += fmt.Sprintf(", %d base cost", o.BaseCost)
+	}
+	if o.WarmUp != 0 {
+		str += fmt.Sprintf(", warm up %s", o.WarmUp)
+	}
+	if o.SustainedRate != 0 || o.SustainedPeriod != 0 {
+		str += fmt.Sprintf(", sustained %d/%s", o.SustainedRate, o.SustainedPeriod)
+	}
+
+	return str
+}
+
+// GoString returns a Go source representation of the options.
+func (o Options) GoString() string {
+	// format burst
+	burst := strconv.FormatInt(o.Burst, 10)
+	if o.Burst == Unlimited {
+		burst = "gcra.Unlimited"
+	}
+
+	// format options
+	str := fmt.Sprintf("gcra.Options{Burst: %s, Rate: %d, Period: %d", burst, o.Rate, int64(o.Period))
+	if o.Exclusive {
+		str += ", Exclusive: true"
+	}
+	if o.MinInterval != 0 {
+		str += fmt.Sprintf(", MinInterval: %d", int64(o.MinInterval))
+	}
+	if o.TokenBytes != 0 {
+		str += fmt.Sprintf(", TokenBytes: %d", o.TokenBytes)
+	}
+	if o.Resolution != 0 {
+		str += fmt.Sprintf(", Resolution: %d", int64(o.Resolution))
+	}
+	if o.Reserve != 0 {
+		str += fmt.Sprintf(", Reserve: %d", o.Reserve)
+	}
+	if o.AllowOverdraft != 0 {
+		str += fmt.Sprintf(", AllowOverdraft: %d", o.AllowOverdraft)
+	}
+	if o.EarlyRejectAt != 0 {
+		str += fmt.Sprintf(", EarlyRejectAt: %d", o.EarlyRejectAt)
+	}
+	if o.NoReset {
+		str += ", NoReset: true"
+	}
+	if o.ClampDurations {
+		str += ", ClampDurations: true"
+	}
+	if o.RejectBackwardsTime {
+		str += ", RejectBackwardsTime: true"
+	}
+	if o.ClockSkewTolerance != 0 {
+		str += fmt.Sprintf(", ClockSkewTolerance: %d", int64(o.ClockSkewTolerance))
+	}
+	if o.BaseCost != 0 {
+		str += fmt.Sprintf(", BaseCost: %d", o.BaseCost)
+	}
+	if o.WarmUp != 0 {
+		str += fmt.Sprintf(", WarmUp: %d", int64(o.WarmUp))
+	}
+	if o.SustainedRate != 0 {
+		str += fmt.Sprintf(", SustainedRate: %d", o.SustainedRate)
+	}
+	if o.SustainedPeriod != 0 {
+		str += fmt.Sprintf(", SustainedPeriod: %d", int64(o.SustainedPeriod))
+	}
+	str += "}"
+
+	return str
+}
+
+func (o Options) check() error {
+	// check unlimited
+	if o.Burst == Unlimited {
+		if !o.valid() {
+			return ErrInvalidParameter
+		}
+		return nil
+	}
+
+	// check options
+	if o.burst(false) <= 0 || !o.valid() {
+		return ErrInvalidParameter
+	} else if roundDiv(int64(o.EmissionInterval()), int64(o.resolution())) <= 0 {
+		return ErrZeroInterval
+	}
+
+	return nil
+}
+
+func (o Options) valid() bool {
+	// check base cost
+	if o.BaseCost < 0 || o.Burst != Unlimited && o.BaseCost > o.Burst {
+		return false
+	}
+
+	// check reserve, regular requests must be left with some tokens
+	if o.Burst != Unlimited && o.Reserve >= o.burst(true) {
+		return false
+	}
+
+	// check early rejection, remaining tokens never exceed the regular burst
+	if o.Burst != Unlimited && o.EarlyRejectAt > o.burst(false) {
+		return false
+	}
+
+	// check sustained rate
+	if o.SustainedRate < 0 || o.SustainedPeriod < 0 || (o.SustainedRate == 0) != (o.SustainedPeriod == 0) {
+		return false
+	}
+
+	// check resolution
+	if o.Resolution < 0 || o.Resolution > 0 && time.Second%o.Resolution != 0 && o.Resolution%time.Second != 0 {
+		return false
+	}
+
+	return o.Rate > 0 && o.Period > 0 && o.MinInterval >= 0 && o.TokenBytes >= 0 && o.Reserve >= 0 && o.AllowOverdraft >= 0 && o.EarlyRejectAt >= 0 && o.ClockSkewTolerance >= 0 && o.WarmUp >= 0
+}
+
+func (o Options) burst(priority bool) int64 {
+	// get burst
+	burst := o.Burst
+	if o.Exclusive {
+		burst--
+	}
+
+	// apply reserve
+	if !priority {
+		burst -= o.Reserve
+	}
+
+	return burst
+}
+
+// horizon returns the largest duration a TAT may legitimately be ahead of the
+// last update of the bucket. It covers a full window plus a single reservation
+// or borrow of up to the burst and overdraft.
+func (o Options) horizon() time.Duration {
+	res := o.resolution()
+	interval := roundDiv(int64(o.EmissionInterval()), int64(res))
+	return time.Duration(mulAdd(mulAdd(interval, 2*(o.Burst+o.AllowOverdraft), 0), int64(res), 0))
+}
+
+// initial returns the bucket to be used for computations. A zero bucket is
+// full, but is drained by the warm up if configured or starts at now if resets
+// are disabled. All functions that interpret the TAT must use it.
+func (o Options) initial(now time.Time, bucket Bucket) Bucket {
+	// check bucket
+	if !time.Time(bucket).IsZero() {
+		return bucket
+	}
+
+	// apply warm up
+	if o.WarmUp > 0 {
+		warmUp := o.WarmUp
+		if window := o.Window(); warmUp > window {
+			warmUp = window
+		}
+		return Bucket(now.Add(warmUp))
+	}
+
+	// start at now if resets are disabled
+	if o.NoReset {
+		return Bucket(now)
+	}
+
+	return bucket
+}
+
+func (o Options) resolution() time.Duration {
+	if o.Resolution == 0 {
+		return time.Nanosecond
+	}
+	return o.Resolution
 }
 
 // Result is the result of a GCRA computation.
@@ -32,23 +438,104 @@ type Result struct {
 	Remaining int64
 	RetryIn   time.Duration
 	ResetIn   time.Duration
+
+	// Consumed is the amount of tokens that have been taken from the bucket.
+	// It is zero if the request has been limited or limiting is disabled.
+	Consumed int64
+
+	// Balance is the token balance of the bucket. It equals the remaining
+	// tokens unless the bucket is in debt due to an overdraft, in which case
+	// it is negative.
+	Balance int64
+
+	// Reason describes why the request has been limited.
+	Reason Reason
+}
+
+// Reason describes why a request has been limited.
+type Reason int
+
+// The available reasons.
+const (
+	// NotLimited is used when the request has not been limited.
+	NotLimited Reason = iota
+
+	// InsufficientTokens is used when the cost of the request exceeded the
+	// available tokens.
+	InsufficientTokens
+
+	// EmptyQuery is used when a zero cost query found the bucket empty.
+	EmptyQuery
+
+	// EarlyRejection is used when the request has been rejected early due to
+	// the EarlyRejectAt option.
+	EarlyRejection
+)
+
+// String implements the fmt.Stringer interface.
+func (r Reason) String() string {
+	switch r {
+	case NotLimited:
+		return "NotLimited"
+	case InsufficientTokens:
+		return "InsufficientTokens"
+	case EmptyQuery:
+		return "EmptyQuery"
+	case EarlyRejection:
+		return "EarlyRejection"
+	default:
+		return "Reason(" + strconv.Itoa(int(r)) + ")"
+	}
+}
+
+func reason(limited bool, cost int64) Reason {
+	if !limited {
+		return NotLimited
+	} else if cost == 0 {
+		return EmptyQuery
+	}
+	return InsufficientTokens
 }
 
 // Generate will create a bucket that contains the specified amount of tokens
-// at this point in time.
+// at this point in time. A bucket generated with the full burst is full at
+// this point in time, while a bucket generated with zero tokens becomes full
+// after the window.
 func Generate(now time.Time, count int64, opts Options) (Bucket, error) {
+	// handle unlimited
+	if opts.Burst == Unlimited {
+		if count < 0 || !opts.valid() {
+			return Bucket{}, ErrInvalidParameter
+		}
+		return Bucket(now), nil
+	}
+
+	// determine burst
+	burst := opts.burst(false)
+
 	// check arguments
-	if count < 0 || opts.Burst <= 0 || opts.Rate <= 0 || opts.Period <= 0 {
+	if count < 0 || burst <= 0 || !opts.valid() {
 		return Bucket{}, ErrInvalidParameter
-	} else if count > opts.Burst {
+	} else if count > burst {
 		return Bucket{}, ErrCostHigherThanBurst
 	}
 
-	// calculate TAT
-	tat := GenerateRaw(now.UnixNano(), count, opts.Burst, opts.Rate, int64(opts.Period))
+	// get resolution and emission interval
+	res := opts.resolution()
+	interval := roundDiv(int64(opts.EmissionInterval()), int64(res))
+	if interval <= 0 {
+		return Bucket{}, ErrZeroInterval
+	}
+
+	// calculate TAT (the emission interval is passed as the period of a
+	// single token)
+	tat, ok := generateRaw(toUnits(now, res), count, burst, 1, interval)
+	if !ok {
+		return Bucket{}, ErrOverflow
+	}
 
 	// create bucket
-	bucket := Bucket(time.Unix(0, tat))
+	bucket := Bucket(fromUnits(tat, res))
 
 	return bucket, nil
 }
@@ -64,59 +551,209 @@ func MustGenerate(now time.Time, count int64, opts Options) Bucket {
 
 // Compute will perform the GCRA. Cost may be zero to query the bucket.
 func Compute(now time.Time, bucket Bucket, cost int64, opts Options) (Bucket, Result, error) {
+	return compute(now, bucket, cost, opts, false, 0)
+}
+
+// MustCompute will call Compute and panic on errors.
+func MustCompute(now time.Time, bucket Bucket, cost int64, opts Options) (Bucket, Result) {
+	bucket, result, err := Compute(now, bucket, cost, opts)
+	if err != nil {
+		panic(err)
+	}
+	return bucket, result
+}
+
+// ComputePriority will perform the GCRA like Compute but may also use the
+// tokens kept in reserve.
+func ComputePriority(now time.Time, bucket Bucket, cost int64, opts Options) (Bucket, Result, error) {
+	return compute(now, bucket, cost, opts, true, 0)
+}
+
+func compute(now time.Time, bucket Bucket, cost int64, opts Options, priority bool, borrow int64) (Bucket, Result, error) {
+	// handle unlimited
+	if opts.Burst == Unlimited {
+		if cost < 0 || !opts.valid() {
+			return bucket, Result{}, ErrInvalidParameter
+		}
+		return bucket, Result{
+			Remaining: math.MaxInt64,
+			Balance:   math.MaxInt64,
+		}, nil
+	}
+
+	// determine burst
+	burst := opts.burst(priority)
+
 	// check arguments
-	if cost < 0 || opts.Burst <= 0 || opts.Rate <= 0 || opts.Period <= 0 {
+	if cost < 0 || burst <= 0 || !opts.valid() {
 		return bucket, Result{}, ErrInvalidParameter
-	} else if cost > opts.Burst {
+	}
+
+	// apply base cost
+	if cost > 0 {
+		cost += opts.BaseCost
+	}
+
+	// check cost
+	if cost > burst+borrow+opts.AllowOverdraft {
 		return bucket, Result{}, ErrCostHigherThanBurst
 	}
 
+	// get resolution and emission interval
+	res := opts.resolution()
+	interval := roundDiv(int64(opts.EmissionInterval()), int64(res))
+	if interval <= 0 {
+		return bucket, Result{}, ErrZeroInterval
+	}
+
 	// calculate TAT
-	tat := time.Time(bucket).UnixNano()
+	tat := toUnits(time.Time(opts.initial(now, bucket)), res)
+
+	// check clock skew (the last update of the bucket happened no earlier
+	// than the horizon before the TAT)
+	if opts.RejectBackwardsTime && !time.Time(bucket).IsZero() {
+		if now.Add(opts.ClockSkewTolerance).Before(time.Time(bucket).Add(-opts.horizon())) {
+			return bucket, Result{}, ErrClockSkew
+		}
+	}
 
-	// compute GCRA
-	newTAT, limited, remaining, retryIn, resetIn := ComputeRaw(tat, now.UnixNano(), opts.Burst, opts.Rate, int64(opts.Period), cost)
+	// compute GCRA (the emission interval is passed as the period of a single
+	// token and borrowed tokens extend the burst)
+	raw := ComputeRaw
+	if opts.NoReset {
+		raw = computeNoReset
+	}
+	var newTAT, balance, retryIn, resetIn int64
+	var limited bool
+	if opts.AllowOverdraft > 0 {
+		newTAT, limited, balance, retryIn, resetIn = computeOverdraft(raw, tat, toUnits(now, res), burst+borrow, opts.AllowOverdraft, interval, cost)
+	} else {
+		newTAT, limited, balance, retryIn, resetIn = raw(tat, toUnits(now, res), burst+borrow, 1, interval, cost)
+	}
+	balance -= borrow
+
+	// check overflow
+	if saturated(newTAT) {
+		return bucket, Result{}, ErrOverflow
+	}
+
+	// apply early rejection
+	reason := reason(limited, cost)
+	if !limited && cost > 0 && earlyReject(toUnits(now, res), tat, cost, balance+cost, opts.EarlyRejectAt) {
+		newTAT, limited, balance, resetIn = tat, true, balance+cost, tat-toUnits(now, res)
+		retryIn = mulAdd(opts.EarlyRejectAt-balance, interval, 0)
+		if resetIn < 0 {
+			resetIn = 0
+		}
+		reason = EarlyRejection
+	}
 
 	// update bucket
-	bucket = Bucket(time.Unix(0, newTAT))
+	bucket = Bucket(fromUnits(newTAT, res))
 
 	// prepare result
 	result := Result{
 		Limited:   limited,
-		Remaining: remaining,
-		RetryIn:   time.Duration(retryIn),
-		ResetIn:   time.Duration(resetIn),
+		Remaining: clamp(balance, 0, burst),
+		RetryIn:   time.Duration(retryIn) * res,
+		ResetIn:   time.Duration(resetIn) * res,
+		Balance:   balance,
+		Reason:    reason,
+	}
+	if !limited {
+		result.Consumed = cost
+	}
+
+	// clamp durations
+	if opts.ClampDurations {
+		window := opts.Window()
+		if result.RetryIn > window {
+			result.RetryIn = window
+		}
+		if result.ResetIn > window {
+			result.ResetIn = window
+		}
 	}
 
 	return bucket, result, nil
 }
 
-// MustCompute will call Compute and panic on errors.
-func MustCompute(now time.Time, bucket Bucket, cost int64, opts Options) (Bucket, Result) {
-	bucket, result, err := Compute(now, bucket, cost, opts)
-	if err != nil {
-		panic(err)
+func computeOverdraft(raw func(tat, now, burst, rate, period, cost int64) (int64, bool, int64, int64, int64), tat, now, burst, overdraft, interval, cost int64) (int64, bool, int64, int64, int64) {
+	// the overdraft extends the burst
+	extended := burst + overdraft
+
+	// check debt
+	_, _, remaining, _, _ := raw(tat, now, extended, 1, interval, 0)
+	if balance := remaining - overdraft; balance < 0 {
+		// limit until debt is repaid
+		retryIn := tat - burst*interval - now
+		if retryIn < 0 {
+			retryIn = 0
+		}
+		return tat, true, balance, retryIn, clamp(tat-now, 0, math.MaxInt64)
 	}
-	return bucket, result
+
+	// compute GCRA
+	newTAT, limited, remaining, retryIn, resetIn := raw(tat, now, extended, 1, interval, cost)
+
+	return newTAT, limited, remaining - overdraft, retryIn, resetIn
 }
 
-// GenerateRaw is the underlying raw computation used in Generate.
+// GenerateRaw is the underlying raw computation used in Generate. The TAT
+// saturates at the int64 range if it overflows.
 func GenerateRaw(now, count, burst, rate, period int64) int64 {
+	tat, _ := generateRaw(now, count, burst, rate, period)
+	return tat
+}
+
+func generateRaw(now, count, burst, rate, period int64) (int64, bool) {
 	// compute variables
 	emissionInterval := roundDiv(period, rate)
 
 	// compute tat
-	tat := now + emissionInterval*(burst-count)
+	tat := mulAdd(emissionInterval, burst-count, now)
 
-	return tat
+	return tat, !saturated(tat)
 }
 
 // ComputeRaw us the underlying raw computation used in Compute.
 func ComputeRaw(tat, now, burst, rate, period, cost int64) (int64, bool, int64, int64, int64) {
+	// try fast path for unit cost
+	if cost == 1 {
+		newTAT, remaining, ok := computeUnit(tat, now, burst, rate, period)
+		if ok {
+			return newTAT, false, remaining, 0, newTAT - now
+		}
+	}
+
+	return computeRaw(tat, now, burst, rate, period, cost)
+}
+
+// RemainingRaw returns the remaining tokens ComputeRaw reports for a zero cost
+// query without computing any other outputs.
+func RemainingRaw(tat, now, burst, rate, period int64) int64 {
 	// compute variables
 	emissionInterval := roundDiv(period, rate)
-	increment := emissionInterval * cost
-	burstOffset := emissionInterval * burst
+
+	// reset TAT if smaller than now
+	if now > tat {
+		tat = now
+	}
+
+	// compute difference like the general computation
+	diff := mulAdd(mulAdd(mulAdd(emissionInterval, burst, 0), -1, tat), -1, now)
+
+	return clamp(roundDiv(diff, emissionInterval), 0, burst)
+}
+
+// computeUnit handles the common case of an admitted unit cost request. It
+// will return false if the request requires the general computation.
+func computeUnit(tat, now, burst, rate, period int64) (int64, int64, bool) {
+	// compute variables
+	emissionInterval := roundDiv(period, rate)
+	if emissionInterval <= 0 {
+		return 0, 0, false
+	}
 
 	// reset TAT if smaller than now
 	if now > tat {
@@ -124,37 +761,220 @@ func ComputeRaw(tat, now, burst, rate, period, cost int64) (int64, bool, int64,
 	}
 
 	// calculate new TAT
-	newTAT := tat + increment
+	newTAT := mulAdd(emissionInterval, 1, tat)
+	if saturated(newTAT) {
+		return 0, 0, false
+	}
+
+	// compute difference
+	diff := mulAdd(mulAdd(-emissionInterval, burst, newTAT), -1, now)
+	if diff < 0 {
+		return 0, 0, false
+	}
+
+	// compute remaining
+	remaining := clamp(roundDiv(diff, emissionInterval), 0, burst)
+
+	return newTAT, remaining, true
+}
+
+func computeRaw(tat, now, burst, rate, period, cost int64) (int64, bool, int64, int64, int64) {
+	e := Explain(tat, now, burst, rate, period, cost)
+	return e.Result, e.Limited, e.Remaining, e.RetryIn, e.ResetIn
+}
+
+func computeNoReset(tat, now, burst, rate, period, cost int64) (int64, bool, int64, int64, int64) {
+	// the TAT may remain in the past, so the reset duration is clamped
+	e := explain(tat, now, burst, rate, period, cost, false)
+	return e.Result, e.Limited, e.Remaining, e.RetryIn, clamp(e.ResetIn, 0, math.MaxInt64)
+}
+
+// Explanation contains the intermediate values and outputs of a raw GCRA
+// computation.
+type Explanation struct {
+	// intermediate values
+	EmissionInterval int64
+	Increment        int64
+	BurstOffset      int64
+	TAT              int64
+	NewTAT           int64
+	AllowAt          int64
+	Diff             int64
+
+	// outputs
+	Result    int64
+	Limited   bool
+	Remaining int64
+	RetryIn   int64
+	ResetIn   int64
+	Reason    Reason
+}
+
+// Explain will perform the general raw computation used by ComputeRaw and
+// return all intermediate values and outputs. The TAT is the TAT after being
+// reset to now and the result is the TAT returned by ComputeRaw. Values that
+// overflow saturate at the int64 range.
+func Explain(tat, now, burst, rate, period, cost int64) Explanation {
+	return explain(tat, now, burst, rate, period, cost, true)
+}
+
+func explain(tat, now, burst, rate, period, cost int64, reset bool) Explanation {
+	// compute variables
+	emissionInterval := roundDiv(period, rate)
+	increment := mulAdd(emissionInterval, cost, 0)
+	burstOffset := mulAdd(emissionInterval, burst, 0)
+
+	// reset TAT if smaller than now
+	if reset && now > tat {
+		tat = now
+	}
+
+	// calculate new TAT
+	newTAT := mulAdd(emissionInterval, cost, tat)
 
 	// compute allow at
-	allowAt := newTAT - burstOffset
+	allowAt := mulAdd(burstOffset, -1, newTAT)
 
 	// compute difference
-	diff := now - allowAt
+	diff := mulAdd(allowAt, -1, now)
 
 	// compute remaining
 	remaining := roundDiv(diff, emissionInterval)
 
+	// prepare explanation
+	e := Explanation{
+		EmissionInterval: emissionInterval,
+		Increment:        increment,
+		BurstOffset:      burstOffset,
+		TAT:              tat,
+		NewTAT:           newTAT,
+		AllowAt:          allowAt,
+		Diff:             diff,
+	}
+
 	// check if not enough
 	if remaining < 0 {
-		remaining := roundDiv(now-(tat-burstOffset), emissionInterval)
-		resetIn := tat - now
-		retryIn := diff * -1
-		return tat, true, remaining, retryIn, resetIn
+		e.Result = tat
+		e.Limited = true
+		e.Remaining = clamp(roundDiv(mulAdd(mulAdd(burstOffset, -1, tat), -1, now), emissionInterval), 0, burst)
+		e.RetryIn = diff * -1
+		e.ResetIn = tat - now
+		e.Reason = reason(true, cost)
+		return e
 	}
 
 	// check if empty
 	if remaining == 0 && increment <= 0 {
-		resetIn := tat - now
-		return tat, true, 0, 0, resetIn
+		e.Result = tat
+		e.Limited = true
+		e.ResetIn = tat - now
+		e.Reason = EmptyQuery
+		return e
+	}
+
+	// set result (a saturated TAT signals an overflow)
+	e.Result = newTAT
+	e.Remaining = clamp(remaining, 0, burst)
+	e.ResetIn = newTAT - now
+
+	return e
+}
+
+// mulAdd returns a*b+c. If the result overflows, it saturates at math.MaxInt64
+// or math.MinInt64 respectively.
+func mulAdd(a, b, c int64) int64 {
+	// multiply
+	m := a * b
+	if a != 0 && (m/a != b || a == -1 && b == math.MinInt64) {
+		if (a > 0) == (b > 0) {
+			return math.MaxInt64
+		}
+		return math.MinInt64
+	}
+
+	// add
+	r := m + c
+	if c > 0 && r < m {
+		return math.MaxInt64
+	} else if c < 0 && r > m {
+		return math.MinInt64
+	}
+
+	return r
+}
+
+// saturated returns whether the value has been saturated by mulAdd.
+func saturated(v int64) bool {
+	return v == math.MaxInt64 || v == math.MinInt64
+}
+
+func toUnits(t time.Time, res time.Duration) int64 {
+	// handle nanoseconds
+	if res == time.Nanosecond {
+		return t.UnixNano()
 	}
 
-	// calculate reset in
-	resetIn := newTAT - now
+	// handle multiples of a second
+	if res >= time.Second {
+		return t.Unix() / int64(res/time.Second)
+	}
 
-	return newTAT, false, remaining, 0, resetIn
+	return t.Unix()*int64(time.Second/res) + int64(t.Nanosecond())/int64(res)
+}
+
+func fromUnits(u int64, res time.Duration) time.Time {
+	// handle nanoseconds
+	if res == time.Nanosecond {
+		return time.Unix(0, u)
+	}
+
+	// handle multiples of a second
+	if res >= time.Second {
+		return time.Unix(u*int64(res/time.Second), 0)
+	}
+
+	// handle fractions of a second
+	perSecond := int64(time.Second / res)
+	return time.Unix(u/perSecond, u%perSecond*int64(res))
+}
+
+func clamp(v, min, max int64) int64 {
+	if v < min {
+		return min
+	} else if v > max {
+		return max
+	}
+	return v
 }
 
 func roundDiv(a, b int64) int64 {
-	return int64(math.Round(float64(a) / float64(b)))
+	// guard against division by zero
+	if b == 0 {
+		return 0
+	}
+
+	// saturate the only quotient that cannot be represented
+	if a == math.MinInt64 && b == -1 {
+		return math.MaxInt64
+	}
+
+	// divide and round half away from zero using the remainder to remain
+	// exact for values that cannot be represented as floats
+	q, r := a/b, a%b
+	if ra, rb := absUint(r), absUint(b); ra >= rb-ra && r != 0 {
+		if (a < 0) != (b < 0) {
+			q--
+		} else {
+			q++
+		}
+	}
+
+	return q
+}
+
+func absUint(v int64) uint64 {
+	if v < 0 {
+		return uint64(-(v + 1)) + 1
+	}
+	return uint64(v)
 }
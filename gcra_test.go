@@ -2,6 +2,9 @@ package gcra
 
 import (
 	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
 	"testing"
 	"time"
 
@@ -36,10 +39,10 @@ func Example() {
 	fmt.Printf("Bucket Offset: %s", time.Time(bucket).Sub(now).String())
 
 	// Output:
-	// {Limited:false Remaining:15 RetryIn:0s ResetIn:3.5s}
-	// {Limited:true Remaining:15 RetryIn:1.5s ResetIn:3.5s}
-	// {Limited:false Remaining:0 RetryIn:0s ResetIn:5s}
-	// {Limited:false Remaining:20 RetryIn:0s ResetIn:3s}
+	// {Limited:false Remaining:15 RetryIn:0s ResetIn:3.5s Consumed:10 Balance:15 Reason:NotLimited}
+	// {Limited:true Remaining:15 RetryIn:1.5s ResetIn:3.5s Consumed:0 Balance:15 Reason:InsufficientTokens}
+	// {Limited:false Remaining:0 RetryIn:0s ResetIn:5s Consumed:15 Balance:0 Reason:NotLimited}
+	// {Limited:false Remaining:20 RetryIn:0s ResetIn:3s Consumed:0 Balance:20 Reason:NotLimited}
 	// Bucket Offset: 3s
 }
 
@@ -61,6 +64,7 @@ func TestGenerate(t *testing.T) {
 		Remaining: 75,
 		RetryIn:   0,
 		ResetIn:   2500 * time.Millisecond,
+		Balance:   75,
 	}, result)
 
 	bucket = MustGenerate(now, 20, opts)
@@ -72,6 +76,7 @@ func TestGenerate(t *testing.T) {
 		Remaining: 20,
 		RetryIn:   0,
 		ResetIn:   8 * time.Second,
+		Balance:   20,
 	}, result)
 
 	bucket = MustGenerate(now, 50, opts)
@@ -83,6 +88,127 @@ func TestGenerate(t *testing.T) {
 		Remaining: 50,
 		RetryIn:   0,
 		ResetIn:   5 * time.Second,
+		Balance:   50,
+	}, result)
+}
+
+func TestGenerateOverflow(t *testing.T) {
+	opts := Options{
+		Burst:  math.MaxInt64 / int64(time.Millisecond),
+		Rate:   1,
+		Period: time.Millisecond,
+	}
+
+	_, err := Generate(now, 0, opts)
+	assert.Equal(t, ErrOverflow, err)
+
+	_, err = Generate(now, opts.Burst-1000, opts)
+	assert.NoError(t, err)
+
+	opts.Burst = math.MaxInt64
+	_, err = Generate(now, 0, opts)
+	assert.Equal(t, ErrOverflow, err)
+
+	assert.Equal(t, int64(math.MaxInt64), GenerateRaw(now.UnixNano(), 0, math.MaxInt64/int64(time.Millisecond), 1, int64(time.Millisecond)))
+	assert.Equal(t, int64(math.MaxInt64), GenerateRaw(0, 0, math.MaxInt64, 1, 2))
+	assert.Equal(t, int64(math.MinInt64), GenerateRaw(0, 0, math.MinInt64/2, 1, 4))
+	assert.Equal(t, int64(math.MinInt64), GenerateRaw(math.MinInt64, 1, 0, 1, 1))
+	assert.Equal(t, int64(30), GenerateRaw(0, 0, 3, 1, 10))
+}
+
+func TestMulAdd(t *testing.T) {
+	assert.Equal(t, int64(7), mulAdd(2, 3, 1))
+	assert.Equal(t, int64(-5), mulAdd(-2, 3, 1))
+	assert.Equal(t, int64(1), mulAdd(0, math.MaxInt64, 1))
+	assert.Equal(t, int64(math.MaxInt64-1), mulAdd(1, math.MaxInt64, -1))
+	assert.Equal(t, int64(math.MinInt64+1), mulAdd(-1, math.MaxInt64, 0))
+	assert.Equal(t, int64(math.MaxInt64), mulAdd(math.MaxInt64, 2, 0))
+	assert.Equal(t, int64(math.MinInt64), mulAdd(math.MaxInt64, -2, 0))
+	assert.Equal(t, int64(math.MaxInt64), mulAdd(math.MinInt64, -1, 0))
+	assert.Equal(t, int64(math.MaxInt64), mulAdd(-1, math.MinInt64, 0))
+	assert.Equal(t, int64(math.MinInt64), mulAdd(math.MinInt64, 2, 0))
+	assert.Equal(t, int64(math.MaxInt64), mulAdd(1, math.MaxInt64, 1))
+	assert.Equal(t, int64(math.MinInt64), mulAdd(1, math.MinInt64, -1))
+	assert.Equal(t, int64(math.MaxInt64), mulAdd(1<<32, 1<<31, 0))
+
+	assert.True(t, saturated(math.MaxInt64))
+	assert.True(t, saturated(math.MinInt64))
+	assert.False(t, saturated(0))
+}
+
+func TestComputeOverflow(t *testing.T) {
+	opts := Options{
+		Burst:  math.MaxInt64 / int64(time.Millisecond),
+		Rate:   1,
+		Period: time.Millisecond,
+	}
+
+	// large cost
+	bucket, result, err := Compute(now, Bucket{}, opts.Burst, opts)
+	assert.Equal(t, ErrOverflow, err)
+	assert.Equal(t, Bucket{}, bucket)
+	assert.Equal(t, Result{}, result)
+
+	// far future bucket
+	future := Bucket(time.Unix(0, math.MaxInt64-10))
+	bucket, _, err = Compute(now, future, 1, opts)
+	assert.Equal(t, ErrOverflow, err)
+	assert.Equal(t, future, bucket)
+
+	// queries do not overflow
+	_, result, err = Compute(now, future, 0, opts)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+
+	// regular bursts are limited instead
+	opts.Burst = 10
+	_, result, err = Compute(now, future, 1, opts)
+	assert.NoError(t, err)
+	assert.True(t, result.Limited)
+}
+
+func TestGenerateBoundaries(t *testing.T) {
+	opts := Options{
+		Burst:  7,
+		Rate:   3,
+		Period: time.Second,
+	}
+
+	assert.Equal(t, 7*333333333*time.Nanosecond, opts.Window())
+
+	bucket := MustGenerate(now, 7, opts)
+	assert.Equal(t, now, time.Time(bucket).UTC())
+
+	_, result := MustCompute(now, bucket, 0, opts)
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 7,
+		RetryIn:   0,
+		ResetIn:   0,
+		Balance:   7,
+	}, result)
+
+	bucket = MustGenerate(now, 0, opts)
+	assert.Equal(t, now.Add(opts.Window()), time.Time(bucket).UTC())
+
+	_, result = MustCompute(now, bucket, 0, opts)
+	assert.Equal(t, Result{
+		Limited:   true,
+		Remaining: 0,
+		RetryIn:   0,
+		ResetIn:   opts.Window(),
+		Balance:   0,
+		Reason:    EmptyQuery,
+	}, result)
+
+	_, result = MustCompute(now, bucket, 1, opts)
+	assert.Equal(t, Result{
+		Limited:   true,
+		Remaining: 0,
+		RetryIn:   333333333 * time.Nanosecond,
+		ResetIn:   opts.Window(),
+		Balance:   0,
+		Reason:    InsufficientTokens,
 	}, result)
 }
 
@@ -99,6 +225,8 @@ func TestCompute(t *testing.T) {
 		Remaining: 3,
 		RetryIn:   0,
 		ResetIn:   1 * time.Second,
+		Consumed:  1,
+		Balance:   3,
 	}, result)
 
 	bucket, result = MustCompute(now, bucket, 1, opts)
@@ -107,6 +235,8 @@ func TestCompute(t *testing.T) {
 		Remaining: 2,
 		RetryIn:   0,
 		ResetIn:   2 * time.Second,
+		Consumed:  1,
+		Balance:   2,
 	}, result)
 
 	bucket, result = MustCompute(now, bucket, 1, opts)
@@ -115,6 +245,8 @@ func TestCompute(t *testing.T) {
 		Remaining: 1,
 		RetryIn:   0,
 		ResetIn:   3 * time.Second,
+		Consumed:  1,
+		Balance:   1,
 	}, result)
 
 	bucket, result = MustCompute(now, bucket, 1, opts)
@@ -123,6 +255,8 @@ func TestCompute(t *testing.T) {
 		Remaining: 0,
 		RetryIn:   0,
 		ResetIn:   4 * time.Second,
+		Consumed:  1,
+		Balance:   0,
 	}, result)
 
 	bucket, result = MustCompute(now, bucket, 1, opts)
@@ -131,6 +265,8 @@ func TestCompute(t *testing.T) {
 		Remaining: 0,
 		RetryIn:   1 * time.Second,
 		ResetIn:   4 * time.Second,
+		Balance:   0,
+		Reason:    InsufficientTokens,
 	}, result)
 
 	bucket, result = MustCompute(now, bucket, 0, opts)
@@ -139,6 +275,8 @@ func TestCompute(t *testing.T) {
 		Remaining: 0,
 		RetryIn:   0,
 		ResetIn:   4 * time.Second,
+		Balance:   0,
+		Reason:    EmptyQuery,
 	}, result)
 
 	now = now.Add(2 * time.Second)
@@ -149,6 +287,8 @@ func TestCompute(t *testing.T) {
 		Remaining: 1,
 		RetryIn:   0,
 		ResetIn:   3 * time.Second,
+		Consumed:  1,
+		Balance:   1,
 	}, result)
 
 	now = now.Add(time.Second)
@@ -159,6 +299,8 @@ func TestCompute(t *testing.T) {
 		Remaining: 1,
 		RetryIn:   0,
 		ResetIn:   3 * time.Second,
+		Consumed:  1,
+		Balance:   1,
 	}, result)
 
 	bucket, result = MustCompute(now, bucket, 2, opts)
@@ -167,9 +309,616 @@ func TestCompute(t *testing.T) {
 		Remaining: 1,
 		RetryIn:   1 * time.Second,
 		ResetIn:   3 * time.Second,
+		Balance:   1,
+		Reason:    InsufficientTokens,
 	}, result)
 }
 
+func TestConsumed(t *testing.T) {
+	opts := Options{
+		Burst:  5,
+		Rate:   1,
+		Period: time.Second,
+	}
+
+	bucket, result := MustCompute(now, Bucket{}, 3, opts)
+	assert.False(t, result.Limited)
+	assert.Equal(t, int64(3), result.Consumed)
+
+	bucket, result = MustCompute(now, bucket, 3, opts)
+	assert.True(t, result.Limited)
+	assert.Equal(t, int64(0), result.Consumed)
+
+	_, result = MustCompute(now, bucket, 0, opts)
+	assert.False(t, result.Limited)
+	assert.Equal(t, int64(0), result.Consumed)
+
+	opts.Burst = Unlimited
+	_, result = MustCompute(now, bucket, 3, opts)
+	assert.False(t, result.Limited)
+	assert.Equal(t, int64(0), result.Consumed)
+}
+
+func TestReserveOption(t *testing.T) {
+	opts := Options{
+		Burst:   10,
+		Rate:    10,
+		Period:  time.Second,
+		Reserve: 2,
+	}
+
+	bucket, result := MustCompute(now, Bucket{}, 0, opts)
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 8,
+		RetryIn:   0,
+		ResetIn:   0,
+		Balance:   8,
+	}, result)
+
+	bucket, result = MustCompute(now, bucket, 8, opts)
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 0,
+		RetryIn:   0,
+		ResetIn:   800 * time.Millisecond,
+		Consumed:  8,
+		Balance:   0,
+	}, result)
+
+	_, result = MustCompute(now, bucket, 1, opts)
+	assert.Equal(t, Result{
+		Limited:   true,
+		Remaining: 0,
+		RetryIn:   100 * time.Millisecond,
+		ResetIn:   800 * time.Millisecond,
+		Balance:   0,
+		Reason:    InsufficientTokens,
+	}, result)
+
+	bucket, result, err := ComputePriority(now, bucket, 1, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 1,
+		RetryIn:   0,
+		ResetIn:   900 * time.Millisecond,
+		Consumed:  1,
+		Balance:   1,
+	}, result)
+
+	bucket, result, err = ComputePriority(now, bucket, 1, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 0,
+		RetryIn:   0,
+		ResetIn:   time.Second,
+		Consumed:  1,
+		Balance:   0,
+	}, result)
+
+	_, result, err = ComputePriority(now, bucket, 1, opts)
+	assert.NoError(t, err)
+	assert.True(t, result.Limited)
+
+	_, result = MustCompute(now.Add(200*time.Millisecond), bucket, 1, opts)
+	assert.True(t, result.Limited)
+
+	_, result = MustCompute(now.Add(300*time.Millisecond), bucket, 1, opts)
+	assert.False(t, result.Limited)
+
+	bucket = MustGenerate(now, 3, opts)
+	_, result = MustCompute(now, bucket, 0, opts)
+	assert.Equal(t, int64(3), result.Remaining)
+
+	_, _, err = Compute(now, Bucket{}, 9, opts)
+	assert.Equal(t, ErrCostHigherThanBurst, err)
+
+	_, _, err = ComputePriority(now, Bucket{}, 10, opts)
+	assert.NoError(t, err)
+
+	_, _, err = Compute(now, Bucket{}, 1, Options{Burst: 2, Rate: 1, Period: 1, Reserve: 2})
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, _, err = ComputePriority(now, Bucket{}, 1, Options{Burst: 2, Rate: 1, Period: 1, Reserve: 2})
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, _, err = ComputePriority(now, Bucket{}, 1, Options{Burst: 2, Rate: 1, Period: 1, Reserve: 1, Exclusive: true})
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, _, err = Compute(now, Bucket{}, 1, Options{Burst: 2, Rate: 1, Period: 1, Reserve: -1})
+	assert.Equal(t, ErrInvalidParameter, err)
+}
+
+func TestReason(t *testing.T) {
+	opts := Options{
+		Burst:  2,
+		Rate:   1,
+		Period: time.Second,
+	}
+
+	bucket, result := MustCompute(now, Bucket{}, 2, opts)
+	assert.False(t, result.Limited)
+	assert.Equal(t, NotLimited, result.Reason)
+
+	_, result = MustCompute(now, bucket, 1, opts)
+	assert.True(t, result.Limited)
+	assert.Equal(t, InsufficientTokens, result.Reason)
+
+	_, result = MustCompute(now, bucket, 0, opts)
+	assert.True(t, result.Limited)
+	assert.Equal(t, EmptyQuery, result.Reason)
+
+	_, result = MustCompute(now.Add(time.Second), bucket, 2, opts)
+	assert.True(t, result.Limited)
+	assert.Equal(t, InsufficientTokens, result.Reason)
+
+	_, result = MustCompute(now.Add(time.Second), bucket, 0, opts)
+	assert.False(t, result.Limited)
+	assert.Equal(t, NotLimited, result.Reason)
+
+	assert.Equal(t, NotLimited, Explain(0, 0, 2, 1, 10, 1).Reason)
+	assert.Equal(t, InsufficientTokens, Explain(20, 0, 2, 1, 10, 1).Reason)
+	assert.Equal(t, EmptyQuery, Explain(20, 0, 2, 1, 10, 0).Reason)
+	assert.Equal(t, EmptyQuery, Explain(40, 0, 2, 1, 10, 0).Reason)
+
+	assert.Equal(t, "NotLimited", NotLimited.String())
+	assert.Equal(t, "InsufficientTokens", InsufficientTokens.String())
+	assert.Equal(t, "EmptyQuery", EmptyQuery.String())
+	assert.Equal(t, "Reason(7)", Reason(7).String())
+}
+
+func TestNoReset(t *testing.T) {
+	opts := Options{
+		Burst:  2,
+		Rate:   1,
+		Period: time.Second,
+	}
+
+	// drain bucket
+	bucket, _ := MustCompute(now, Bucket{}, 2, opts)
+	_, result := MustCompute(now, bucket, 1, opts)
+	assert.True(t, result.Limited)
+
+	// with reset, a long idle period restores the burst
+	later := now.Add(10 * time.Second)
+	var admitted int
+	for b := bucket; ; admitted++ {
+		var result Result
+		b, result = MustCompute(later, b, 1, opts)
+		if result.Limited {
+			break
+		}
+	}
+	assert.Equal(t, 2, admitted)
+
+	// without reset, the idle period is accumulated as credit
+	opts.NoReset = true
+	admitted = 0
+	for b := bucket; ; admitted++ {
+		var result Result
+		b, result = MustCompute(later, b, 1, opts)
+		if result.Limited {
+			break
+		}
+		assert.True(t, result.Remaining <= 2)
+		assert.True(t, result.ResetIn >= 0)
+	}
+	assert.Equal(t, 10, admitted)
+
+	// zero buckets start at now
+	bucket, result = MustCompute(now, Bucket{}, 2, opts)
+	assert.False(t, result.Limited)
+	assert.Equal(t, Bucket(time.Unix(0, now.Add(2*time.Second).UnixNano())), bucket)
+	_, result = MustCompute(now, bucket, 1, opts)
+	assert.True(t, result.Limited)
+}
+
+func TestClampDurations(t *testing.T) {
+	opts := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+	}
+
+	// import far future bucket
+	bucket := Bucket(time.Unix(0, now.AddDate(3, 0, 0).UnixNano()))
+
+	_, result := MustCompute(now, bucket, 1, opts)
+	assert.True(t, result.Limited)
+	assert.True(t, result.RetryIn > opts.Window())
+	assert.True(t, result.ResetIn > opts.Window())
+
+	opts.ClampDurations = true
+	newBucket, result := MustCompute(now, bucket, 1, opts)
+	assert.Equal(t, bucket, newBucket)
+	assert.True(t, result.Limited)
+	assert.Equal(t, time.Second, result.RetryIn)
+	assert.Equal(t, time.Second, result.ResetIn)
+	assert.True(t, result.ResetIn <= opts.Window())
+
+	// regular durations are unaffected
+	_, result = MustCompute(now, MustGenerate(now, 5, opts), 1, opts)
+	assert.Equal(t, Result{
+		Remaining: 4,
+		ResetIn:   600 * time.Millisecond,
+		Consumed:  1,
+		Balance:   4,
+	}, result)
+}
+
+func TestRejectBackwardsTime(t *testing.T) {
+	opts := Options{
+		Burst:               10,
+		Rate:                10,
+		Period:              time.Second,
+		RejectBackwardsTime: true,
+		ClockSkewTolerance:  100 * time.Millisecond,
+	}
+
+	// drain bucket (the TAT may be up to two windows ahead of the last update)
+	bucket, result := MustCompute(now, Bucket{}, 10, opts)
+	assert.False(t, result.Limited)
+
+	// within tolerance
+	_, result, err := Compute(now.Add(-1100*time.Millisecond), bucket, 0, opts)
+	assert.NoError(t, err)
+	assert.True(t, result.Limited)
+
+	// beyond tolerance
+	_, _, err = Compute(now.Add(-1101*time.Millisecond), bucket, 0, opts)
+	assert.Equal(t, ErrClockSkew, err)
+
+	// partially drained buckets allow less skew
+	bucket = MustGenerate(now, 5, opts)
+	_, _, err = Compute(now.Add(-1700*time.Millisecond), bucket, 0, opts)
+	assert.Equal(t, ErrClockSkew, err)
+	_, _, err = Compute(now.Add(-1500*time.Millisecond), bucket, 0, opts)
+	assert.NoError(t, err)
+
+	// zero buckets are never rejected
+	_, _, err = Compute(now.Add(-time.Hour), Bucket{}, 0, opts)
+	assert.NoError(t, err)
+
+	// disabled
+	opts.RejectBackwardsTime = false
+	_, _, err = Compute(now.Add(-time.Hour), bucket, 0, opts)
+	assert.NoError(t, err)
+
+	_, _, err = Compute(now, bucket, 0, Options{Burst: 1, Rate: 1, Period: 1, ClockSkewTolerance: -1})
+	assert.Equal(t, ErrInvalidParameter, err)
+}
+
+func TestRejectBackwardsTimeReserve(t *testing.T) {
+	opts := Options{
+		Burst:               10,
+		Rate:                10,
+		Period:              time.Second,
+		RejectBackwardsTime: true,
+	}
+
+	// drain bucket
+	bucket, result := MustCompute(now, Bucket{}, 10, opts)
+	assert.False(t, result.Limited)
+
+	// reserve a full burst
+	reservation, bucket, err := Reserve(now, bucket, 10, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second, reservation.Delay)
+	assert.Equal(t, 2*time.Second, time.Time(bucket).Sub(now))
+
+	// later requests are not rejected
+	_, result, err = Compute(now.Add(100*time.Millisecond), bucket, 1, opts)
+	assert.NoError(t, err)
+	assert.True(t, result.Limited)
+	_, _, err = Compute(now, bucket, 0, opts)
+	assert.NoError(t, err)
+}
+
+func TestBaseCost(t *testing.T) {
+	opts := Options{
+		Burst:    10,
+		Rate:     1,
+		Period:   time.Second,
+		BaseCost: 2,
+	}
+
+	bucket, result := MustCompute(now, Bucket{}, 3, opts)
+	assert.Equal(t, Result{
+		Remaining: 5,
+		ResetIn:   5 * time.Second,
+		Consumed:  5,
+		Balance:   5,
+	}, result)
+
+	_, result = MustCompute(now, bucket, 0, opts)
+	assert.Equal(t, Result{
+		Remaining: 5,
+		ResetIn:   5 * time.Second,
+		Balance:   5,
+	}, result)
+
+	_, result = MustCompute(now, bucket, 4, opts)
+	assert.True(t, result.Limited)
+	assert.Equal(t, time.Second, result.RetryIn)
+
+	_, _, err := Compute(now, bucket, 9, opts)
+	assert.Equal(t, ErrCostHigherThanBurst, err)
+
+	// a base cost equal to the burst limits everything but queries
+	opts.BaseCost = 10
+	_, result = MustCompute(now, Bucket{}, 0, opts)
+	assert.False(t, result.Limited)
+	_, _, err = Compute(now, Bucket{}, 1, opts)
+	assert.Equal(t, ErrCostHigherThanBurst, err)
+
+	opts.BaseCost = 11
+	_, _, err = Compute(now, Bucket{}, 0, opts)
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	opts.BaseCost = -1
+	_, _, err = Compute(now, Bucket{}, 0, opts)
+	assert.Equal(t, ErrInvalidParameter, err)
+}
+
+func TestWarmUp(t *testing.T) {
+	opts := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+		WarmUp: time.Second,
+	}
+
+	// start
+	bucket, result := MustCompute(now, Bucket{}, 0, opts)
+	assert.True(t, result.Limited)
+	assert.Equal(t, int64(0), result.Remaining)
+	assert.Equal(t, time.Second, result.ResetIn)
+
+	// midpoint
+	_, result = MustCompute(now.Add(500*time.Millisecond), bucket, 0, opts)
+	assert.Equal(t, int64(5), result.Remaining)
+	_, result = MustCompute(now.Add(500*time.Millisecond), bucket, 6, opts)
+	assert.True(t, result.Limited)
+
+	// end
+	_, result = MustCompute(now.Add(time.Second), bucket, 10, opts)
+	assert.False(t, result.Limited)
+
+	// shorter warm up starts partially drained
+	opts.WarmUp = 300 * time.Millisecond
+	_, result = MustCompute(now, Bucket{}, 0, opts)
+	assert.Equal(t, int64(7), result.Remaining)
+
+	// longer warm up is capped at the window
+	opts.WarmUp = time.Hour
+	_, result = MustCompute(now, Bucket{}, 0, opts)
+	assert.Equal(t, time.Second, result.ResetIn)
+
+	// existing buckets are unaffected
+	_, result = MustCompute(now, MustGenerate(now, 10, opts), 10, opts)
+	assert.False(t, result.Limited)
+}
+
+func TestRemainingClamp(t *testing.T) {
+	opts := Options{
+		Burst:  7,
+		Rate:   3,
+		Period: time.Second,
+	}
+
+	for _, offset := range []time.Duration{time.Nanosecond, time.Second, 24 * time.Hour, 100 * 365 * 24 * time.Hour} {
+		_, result := MustCompute(now, Bucket(now.Add(-offset)), 0, opts)
+		assert.Equal(t, Result{
+			Limited:   false,
+			Remaining: 7,
+			RetryIn:   0,
+			ResetIn:   0,
+			Balance:   7,
+		}, result)
+	}
+
+	_, result := MustCompute(now, Bucket{}, 0, opts)
+	assert.Equal(t, int64(7), result.Remaining)
+
+	opts.Reserve = 3
+
+	bucket, _, err := ComputePriority(now, Bucket{}, 7, opts)
+	assert.NoError(t, err)
+
+	_, result = MustCompute(now, bucket, 0, opts)
+	assert.Equal(t, int64(0), result.Remaining)
+
+	_, result = MustCompute(now, bucket, 1, opts)
+	assert.Equal(t, int64(0), result.Remaining)
+	assert.True(t, result.Limited)
+
+	for i := int64(0); i < 100; i++ {
+		_, _, remaining, _, _ := ComputeRaw(-i, 0, 3, 1, 10, 0)
+		assert.True(t, remaining <= 3)
+		_, _, remaining, _, _ = ComputeRaw(100+i, 0, 3, 1, 10, 1)
+		assert.True(t, remaining >= 0)
+	}
+}
+
+func TestOverdraft(t *testing.T) {
+	opts := Options{
+		Burst:          5,
+		Rate:           1,
+		Period:         time.Second,
+		AllowOverdraft: 3,
+	}
+
+	bucket, result := MustCompute(now, Bucket{}, 2, opts)
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 3,
+		RetryIn:   0,
+		ResetIn:   2 * time.Second,
+		Consumed:  2,
+		Balance:   3,
+	}, result)
+
+	bucket, result = MustCompute(now, bucket, 5, opts)
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 0,
+		RetryIn:   0,
+		ResetIn:   7 * time.Second,
+		Consumed:  5,
+		Balance:   -2,
+	}, result)
+
+	_, result = MustCompute(now, bucket, 1, opts)
+	assert.Equal(t, Result{
+		Limited:   true,
+		Remaining: 0,
+		RetryIn:   2 * time.Second,
+		ResetIn:   7 * time.Second,
+		Balance:   -2,
+		Reason:    InsufficientTokens,
+	}, result)
+
+	_, result = MustCompute(now.Add(time.Second), bucket, 0, opts)
+	assert.Equal(t, Result{
+		Limited:   true,
+		Remaining: 0,
+		RetryIn:   time.Second,
+		ResetIn:   6 * time.Second,
+		Balance:   -1,
+		Reason:    EmptyQuery,
+	}, result)
+
+	_, result = MustCompute(now.Add(2*time.Second), bucket, 0, opts)
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 0,
+		RetryIn:   0,
+		ResetIn:   5 * time.Second,
+		Balance:   0,
+	}, result)
+
+	_, result = MustCompute(now.Add(2*time.Second), bucket, 4, opts)
+	assert.True(t, result.Limited)
+	assert.Equal(t, time.Second, result.RetryIn)
+
+	_, result = MustCompute(now.Add(2*time.Second), bucket, 3, opts)
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 0,
+		RetryIn:   0,
+		ResetIn:   8 * time.Second,
+		Consumed:  3,
+		Balance:   -3,
+	}, result)
+
+	_, result = MustCompute(now.Add(7*time.Second), bucket, 0, opts)
+	assert.Equal(t, int64(5), result.Remaining)
+	assert.Equal(t, int64(5), result.Balance)
+
+	_, _, err := Compute(now, Bucket{}, 9, opts)
+	assert.Equal(t, ErrCostHigherThanBurst, err)
+
+	_, _, err = Compute(now, Bucket{}, 1, Options{Burst: 1, Rate: 1, Period: 1, AllowOverdraft: -1})
+	assert.Equal(t, ErrInvalidParameter, err)
+}
+
+// reference computes the expected result from first principles using exact
+// rational arithmetic, independently of ComputeRaw.
+func reference(tat, now time.Time, cost int64, opts Options) (time.Time, Result) {
+	// the emission interval is rounded to whole nanoseconds
+	interval := new(big.Rat).SetFrac64(int64(opts.Period), opts.Rate)
+	ei := roundRat(interval)
+
+	// an idle bucket is full
+	if now.After(tat) {
+		tat = now
+	}
+
+	// compute available tokens
+	available := new(big.Rat).SetFrac64(int64(now.Sub(tat))+ei*opts.Burst, ei)
+	after := new(big.Rat).Sub(available, new(big.Rat).SetInt64(cost))
+
+	// reject if the tokens would go negative
+	if roundRat(after) < 0 {
+		retryIn := new(big.Rat).Mul(new(big.Rat).Neg(after), new(big.Rat).SetInt64(ei))
+		return tat, Result{
+			Limited:   true,
+			Remaining: roundRat(available),
+			RetryIn:   time.Duration(roundRat(retryIn)),
+			ResetIn:   tat.Sub(now),
+			Balance:   roundRat(available),
+			Reason:    reason(true, cost),
+		}
+	}
+
+	// reject queries of empty buckets
+	if cost == 0 && roundRat(available) == 0 {
+		return tat, Result{
+			Limited: true,
+			ResetIn: tat.Sub(now),
+			Reason:  EmptyQuery,
+		}
+	}
+
+	// admit request
+	tat = tat.Add(time.Duration(cost * ei))
+	return tat, Result{
+		Remaining: roundRat(after),
+		ResetIn:   tat.Sub(now),
+		Consumed:  cost,
+		Balance:   roundRat(after),
+	}
+}
+
+func roundRat(r *big.Rat) int64 {
+	// round half away from zero
+	num := new(big.Int).Abs(r.Num())
+	num.Mul(num, big.NewInt(2))
+	num.Add(num, r.Denom())
+	num.Quo(num, new(big.Int).Mul(r.Denom(), big.NewInt(2)))
+	if r.Sign() < 0 {
+		num.Neg(num)
+	}
+	return num.Int64()
+}
+
+func TestReferenceMatrix(t *testing.T) {
+	start := time.Date(2022, 1, 23, 10, 52, 0, 0, time.UTC)
+
+	for _, opts := range []Options{
+		{Burst: 50, Rate: 10, Period: time.Second},
+		{Burst: 4, Rate: 10, Period: 10 * time.Second},
+		{Burst: 7, Rate: 3, Period: time.Second},
+		{Burst: 1, Rate: 1, Period: time.Minute},
+		{Burst: 100, Rate: 7, Period: 999 * time.Millisecond},
+		{Burst: 3, Rate: 2, Period: 3},
+	} {
+		for _, count := range []int64{0, 1, opts.Burst / 2, opts.Burst} {
+			bucket := MustGenerate(start, count, opts)
+			tat := time.Time(bucket)
+
+			now := start
+			for i, cost := range []int64{0, 1, 2, opts.Burst, 1, 0, 3, 1} {
+				if cost > opts.Burst {
+					continue
+				}
+
+				expectedTAT, expected := reference(tat, now, cost, opts)
+
+				var result Result
+				bucket, result = MustCompute(now, bucket, cost, opts)
+				assert.Equal(t, expected, result, "%s count=%d step=%d", opts, count, i)
+				assert.True(t, expectedTAT.Equal(time.Time(bucket)), "%s count=%d step=%d", opts, count, i)
+
+				tat = time.Time(bucket)
+				now = now.Add(opts.EmissionInterval()*time.Duration(i) + time.Duration(i*i))
+			}
+		}
+	}
+}
+
 func TestGenerateErrors(t *testing.T) {
 	_, err := Generate(now, -1, Options{Burst: 1, Rate: 1, Period: 1})
 	assert.Equal(t, ErrInvalidParameter, err)
@@ -192,38 +941,640 @@ func TestGenerateErrors(t *testing.T) {
 }
 
 func TestComputeErrors(t *testing.T) {
-	_, _, err := Compute(now, Bucket{}, -1, Options{1, 1, 1})
+	_, _, err := Compute(now, Bucket{}, -1, Options{Burst: 1, Rate: 1, Period: 1})
 	assert.Equal(t, ErrInvalidParameter, err)
 
-	_, _, err = Compute(now, Bucket{}, 1, Options{0, 1, 1})
+	_, _, err = Compute(now, Bucket{}, 1, Options{Burst: 0, Rate: 1, Period: 1})
 	assert.Equal(t, ErrInvalidParameter, err)
 
-	_, _, err = Compute(now, Bucket{}, 1, Options{1, 0, 1})
+	_, _, err = Compute(now, Bucket{}, 1, Options{Burst: 1, Rate: 0, Period: 1})
 	assert.Equal(t, ErrInvalidParameter, err)
 
-	_, _, err = Compute(now, Bucket{}, 1, Options{1, 1, 0})
+	_, _, err = Compute(now, Bucket{}, 1, Options{Burst: 1, Rate: 1, Period: 0})
 	assert.Equal(t, ErrInvalidParameter, err)
 
-	_, _, err = Compute(now, Bucket{}, 2, Options{1, 1, 1})
+	_, _, err = Compute(now, Bucket{}, 2, Options{Burst: 1, Rate: 1, Period: 1})
 	assert.Equal(t, ErrCostHigherThanBurst, err)
 
 	assert.Panics(t, func() {
-		MustCompute(now, Bucket{}, 1, Options{0, 1, 1})
+		MustCompute(now, Bucket{}, 1, Options{Burst: 0, Rate: 1, Period: 1})
 	})
 }
 
-func BenchmarkCompute(b *testing.B) {
+func TestUnlimited(t *testing.T) {
 	opts := Options{
-		Burst:  int64(b.N),
+		Burst:  Unlimited,
 		Rate:   10,
-		Period: 10 * time.Second,
+		Period: time.Second,
+	}
+
+	bucket := MustGenerate(now, 5, opts)
+	assert.Equal(t, Bucket(now), bucket)
+
+	for i := 0; i < 100; i++ {
+		newBucket, result := MustCompute(now, bucket, 1000, opts)
+		assert.Equal(t, bucket, newBucket)
+		assert.Equal(t, Result{
+			Limited:   false,
+			Remaining: math.MaxInt64,
+			RetryIn:   0,
+			ResetIn:   0,
+			Balance:   math.MaxInt64,
+		}, result)
+	}
+
+	_, result := MustCompute(now, Bucket{}, 0, opts)
+	assert.Equal(t, Result{
+		Remaining: math.MaxInt64,
+		Balance:   math.MaxInt64,
+	}, result)
+
+	_, err := Generate(now, -1, Options{Burst: Unlimited, Rate: 1, Period: 1})
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, err = Generate(now, 0, Options{Burst: Unlimited, Rate: 0, Period: 1})
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, _, err = Compute(now, Bucket{}, -1, Options{Burst: Unlimited, Rate: 1, Period: 1})
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, _, err = Compute(now, Bucket{}, 1, Options{Burst: Unlimited, Rate: 0, Period: 1})
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, _, err = Compute(now, Bucket{}, 1, Options{Burst: Unlimited, Rate: 1, Period: 0})
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, _, err = Compute(now, Bucket{}, 1, Options{Burst: -2, Rate: 1, Period: 1})
+	assert.Equal(t, ErrInvalidParameter, err)
+}
+
+func TestExclusive(t *testing.T) {
+	opts := Options{
+		Burst:  3,
+		Rate:   1,
+		Period: time.Second,
+	}
+
+	bucket := MustGenerate(now, 2, opts)
+
+	bucket, result := MustCompute(now, bucket, 2, opts)
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 0,
+		RetryIn:   0,
+		ResetIn:   3 * time.Second,
+		Consumed:  2,
+		Balance:   0,
+	}, result)
+
+	_, result = MustCompute(now, bucket, 1, opts)
+	assert.Equal(t, Result{
+		Limited:   true,
+		Remaining: 0,
+		RetryIn:   1 * time.Second,
+		ResetIn:   3 * time.Second,
+		Balance:   0,
+		Reason:    InsufficientTokens,
+	}, result)
+
+	opts.Exclusive = true
+
+	bucket = MustGenerate(now, 2, opts)
+
+	bucket, result = MustCompute(now, bucket, 2, opts)
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 0,
+		RetryIn:   0,
+		ResetIn:   2 * time.Second,
+		Consumed:  2,
+		Balance:   0,
+	}, result)
+
+	_, result = MustCompute(now, bucket, 1, opts)
+	assert.Equal(t, Result{
+		Limited:   true,
+		Remaining: 0,
+		RetryIn:   1 * time.Second,
+		ResetIn:   2 * time.Second,
+		Balance:   0,
+		Reason:    InsufficientTokens,
+	}, result)
+
+	_, result = MustCompute(now, Bucket{}, 0, opts)
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 2,
+		RetryIn:   0,
+		ResetIn:   0,
+		Balance:   2,
+	}, result)
+
+	_, err := Generate(now, 3, opts)
+	assert.Equal(t, ErrCostHigherThanBurst, err)
+
+	_, _, err = Compute(now, Bucket{}, 3, opts)
+	assert.Equal(t, ErrCostHigherThanBurst, err)
+
+	_, _, err = Compute(now, Bucket{}, 0, Options{Burst: 1, Rate: 1, Period: 1, Exclusive: true})
+	assert.Equal(t, ErrInvalidParameter, err)
+}
+
+func TestEmissionInterval(t *testing.T) {
+	assert.Equal(t, 100*time.Millisecond, Options{Burst: 1, Rate: 10, Period: time.Second}.EmissionInterval())
+	assert.Equal(t, 333333333*time.Nanosecond, Options{Burst: 1, Rate: 3, Period: time.Second}.EmissionInterval())
+	assert.Equal(t, 666666667*time.Nanosecond, Options{Burst: 1, Rate: 3, Period: 2 * time.Second}.EmissionInterval())
+	assert.Equal(t, time.Duration(0), Options{Burst: 1, Rate: 3, Period: 1}.EmissionInterval())
+	assert.Equal(t, time.Millisecond, Options{Burst: 1, Rate: 3, Period: 1, MinInterval: time.Millisecond}.EmissionInterval())
+	assert.Equal(t, time.Second, Options{Burst: 1, Rate: 1, Period: time.Second, MinInterval: time.Millisecond}.EmissionInterval())
+}
+
+func TestPerSecond(t *testing.T) {
+	assert.Equal(t, 10.0, Options{Burst: 1, Rate: 10, Period: time.Second}.PerSecond())
+	assert.Equal(t, 0.5, Options{Burst: 1, Rate: 1, Period: 2 * time.Second}.PerSecond())
+	assert.Equal(t, 1000.0, Options{Burst: 1, Rate: 1e6, Period: time.Second, MinInterval: time.Millisecond}.PerSecond())
+	assert.Equal(t, 0.0, Options{}.PerSecond())
+}
+
+func TestRateError(t *testing.T) {
+	assert.Equal(t, 0.0, Options{Burst: 1, Rate: 10, Period: time.Second}.RateError())
+	assert.Equal(t, 0.0, Options{Burst: 1, Rate: 1e6, Period: time.Second, MinInterval: time.Millisecond}.RateError())
+	assert.InDelta(t, 0.0005, Options{Burst: 1, Rate: 3, Period: 2 * time.Microsecond}.RateError(), 0.00001)
+	assert.InDelta(t, 1.0/3, Options{Burst: 1, Rate: 3, Period: 4}.RateError(), 0.00001)
+	assert.InDelta(t, 1.0/3, Options{Burst: 1, Rate: 3, Period: 2 * time.Millisecond, Resolution: time.Millisecond}.RateError(), 0.00001)
+	assert.True(t, math.IsInf(Options{Burst: 1, Rate: 3, Period: 1}.RateError(), 1))
+	assert.True(t, math.IsInf(Options{}.RateError(), 1))
+}
+
+func TestMicrosecondRate(t *testing.T) {
+	for _, opts := range []Options{
+		{Burst: 3, Rate: 3, Period: 2 * time.Microsecond},
+		{Burst: 5, Rate: 7, Period: 3 * time.Microsecond},
+		{Burst: 10, Rate: 10, Period: 9 * time.Microsecond},
+	} {
+		// admit requests every 100ns for 10ms
+		var bucket Bucket
+		var admitted int64
+		duration := 10 * time.Millisecond
+		for d := time.Duration(0); d < duration; d += 100 * time.Nanosecond {
+			var result Result
+			bucket, result = MustCompute(now.Add(d), bucket, 1, opts)
+			if !result.Limited {
+				admitted++
+			}
+		}
+
+		// check long-run rate (excluding the initial burst)
+		expected := float64(duration) / float64(opts.Period) * float64(opts.Rate)
+		assert.InDelta(t, expected, float64(admitted-opts.Burst), expected*(opts.RateError()+0.001), opts.String())
 	}
+}
+
+func TestEquivalentOptions(t *testing.T) {
+	for _, pair := range [][2]Options{
+		{{Burst: 5, Rate: 10, Period: time.Second}, {Burst: 5, Rate: 100, Period: 10 * time.Second}},
+		{{Burst: 5, Rate: 10, Period: time.Second}, {Burst: 5, Rate: 1, Period: 100 * time.Millisecond}},
+		{{Burst: 7, Rate: 3, Period: time.Second}, {Burst: 7, Rate: 6, Period: 2 * time.Second}},
+		{{Burst: 3, Rate: 7, Period: time.Second}, {Burst: 3, Rate: 70, Period: 10 * time.Second}},
+	} {
+		a, b := pair[0], pair[1]
+		assert.Equal(t, a.EmissionInterval(), b.EmissionInterval())
+
+		bucketA, bucketB := Bucket{}, Bucket{}
+		at := now
+		for i, cost := range []int64{1, 2, 0, 3, 1, 1, 0, 2, 1} {
+			var resultA, resultB Result
+			bucketA, resultA = MustCompute(at, bucketA, cost, a)
+			bucketB, resultB = MustCompute(at, bucketB, cost, b)
+			assert.Equal(t, resultA, resultB, "%s step=%d", a, i)
+			assert.Equal(t, bucketA, bucketB, "%s step=%d", a, i)
+			at = at.Add(time.Duration(i) * 37 * time.Millisecond)
+		}
+	}
+}
+
+func TestOptionsStricter(t *testing.T) {
+	table := []struct {
+		a, b Options
+		res  Options
+	}{
+		{
+			a:   Options{Burst: 10, Rate: 10, Period: time.Second},
+			b:   Options{Burst: 10, Rate: 5, Period: time.Second},
+			res: Options{Burst: 10, Rate: 5, Period: time.Second},
+		},
+		{
+			a:   Options{Burst: 10, Rate: 60, Period: time.Minute},
+			b:   Options{Burst: 10, Rate: 2, Period: time.Second},
+			res: Options{Burst: 10, Rate: 60, Period: time.Minute},
+		},
+		{
+			a:   Options{Burst: 10, Rate: 3600, Period: time.Hour},
+			b:   Options{Burst: 5, Rate: 1, Period: time.Second},
+			res: Options{Burst: 5, Rate: 1, Period: time.Second},
+		},
+		{
+			a:   Options{Burst: 5, Rate: 10, Period: 10 * time.Second},
+			b:   Options{Burst: 10, Rate: 1, Period: time.Second},
+			res: Options{Burst: 5, Rate: 10, Period: 10 * time.Second},
+		},
+		{
+			a:   Options{Burst: 100, Rate: 1000, Period: time.Second},
+			b:   Options{Burst: 1, Rate: 1, Period: time.Second, MinInterval: time.Millisecond},
+			res: Options{Burst: 1, Rate: 1, Period: time.Second, MinInterval: time.Millisecond},
+		},
+		{
+			a:   Options{Burst: Unlimited, Rate: 1, Period: time.Hour},
+			b:   Options{Burst: 100, Rate: 100, Period: time.Second},
+			res: Options{Burst: 100, Rate: 100, Period: time.Second},
+		},
+	}
+
+	for _, item := range table {
+		assert.Equal(t, item.res, item.a.Stricter(item.b))
+		assert.Equal(t, item.res, item.b.Stricter(item.a))
+	}
+}
+
+func TestOptionsString(t *testing.T) {
+	opts := Options{Burst: 100, Rate: 10, Period: time.Second}
+	assert.Equal(t, "100 burst, 10/1s (emit 100ms)", opts.String())
+	assert.Equal(t, "100 burst, 10/1s (emit 100ms)", fmt.Sprintf("%v", opts))
+	assert.Equal(t, "gcra.Options{Burst: 100, Rate: 10, Period: 1000000000}", fmt.Sprintf("%#v", opts))
+
+	opts = Options{Burst: 5, Rate: 3, Period: time.Second}
+	assert.Equal(t, "5 burst, 3/1s (emit 333.333333ms)", opts.String())
+	assert.Equal(t, "gcra.Options{Burst: 5, Rate: 3, Period: 1000000000}", opts.GoString())
+
+	opts = Options{Burst: 5, Rate: 3, Period: 2, Exclusive: true, MinInterval: time.Millisecond, TokenBytes: 1024, Resolution: time.Microsecond, Reserve: 1}
+	assert.Equal(t, "5 burst, 3/2ns (emit 1ms), exclusive, min 1ms, 1024 bytes/token, resolution 1µs, 1 reserved", opts.String())
+	assert.Equal(t, "gcra.Options{Burst: 5, Rate: 3, Period: 2, Exclusive: true, MinInterval: 1000000, TokenBytes: 1024, Resolution: 1000, Reserve: 1}", opts.GoString())
+
+	opts = Options{Burst: 5, Rate: 1, Period: time.Second, AllowOverdraft: 2, EarlyRejectAt: 3, NoReset: true, ClampDurations: true}
+	assert.Equal(t, "5 burst, 1/1s (emit 1s), 2 overdraft, early reject at 3, no reset, clamp durations", opts.String())
+	assert.Equal(t, "gcra.Options{Burst: 5, Rate: 1, Period: 1000000000, AllowOverdraft: 2, EarlyRejectAt: 3, NoReset: true, ClampDurations: true}", opts.GoString())
+
+	opts = Options{Burst: 5, Rate: 1, Period: time.Second, RejectBackwardsTime: true, ClockSkewTolerance: time.Second}
+	assert.Equal(t, "5 burst, 1/1s (emit 1s), reject backwards time, skew tolerance 1s", opts.String())
+	assert.Equal(t, "gcra.Options{Burst: 5, Rate: 1, Period: 1000000000, RejectBackwardsTime: true, ClockSkewTolerance: 1000000000}", opts.GoString())
 
-	b.ReportAllocs()
-	b.ResetTimer()
+	opts = Options{Burst: 5, Rate: 1, Period: time.Second, BaseCost: 2, WarmUp: time.Minute}
+	assert.Equal(t, "5 burst, 1/1s (emit 1s), 2 base cost, warm up 1m0s", opts.String())
+	assert.Equal(t, "gcra.Options{Burst: 5, Rate: 1, Period: 1000000000, BaseCost: 2, WarmUp: 60000000000}", opts.GoString())
+
+	opts = Options{Burst: 5, Rate: 1, Period: time.Second, SustainedRate: 20, SustainedPeriod: time.Minute}
+	assert.Equal(t, "5 burst, 1/1s (emit 1s), sustained 20/1m0s", opts.String())
+	assert.Equal(t, "gcra.Options{Burst: 5, Rate: 1, Period: 1000000000, SustainedRate: 20, SustainedPeriod: 60000000000}", opts.GoString())
+
+	opts = Options{Burst: Unlimited, Rate: 1, Period: time.Minute}
+	assert.Equal(t, "unlimited burst, 1/1m0s (emit 1m0s)", opts.String())
+	assert.Equal(t, "gcra.Options{Burst: gcra.Unlimited, Rate: 1, Period: 60000000000}", opts.GoString())
+}
+
+func TestMinInterval(t *testing.T) {
+	opts := Options{
+		Burst:       3,
+		Rate:        1e12,
+		Period:      time.Second,
+		MinInterval: time.Millisecond,
+	}
 
 	var bucket Bucket
-	for i := 0; i < b.N; i++ {
-		bucket, _ = MustCompute(now, bucket, 1, opts)
+	var result Result
+	for i := 0; i < 3; i++ {
+		bucket, result = MustCompute(now, bucket, 1, opts)
+		assert.False(t, result.Limited)
+	}
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 0,
+		RetryIn:   0,
+		ResetIn:   3 * time.Millisecond,
+		Consumed:  1,
+		Balance:   0,
+	}, result)
+
+	bucket, result = MustCompute(now, bucket, 1, opts)
+	assert.Equal(t, Result{
+		Limited:   true,
+		Remaining: 0,
+		RetryIn:   time.Millisecond,
+		ResetIn:   3 * time.Millisecond,
+		Balance:   0,
+		Reason:    InsufficientTokens,
+	}, result)
+
+	_, result = MustCompute(now.Add(time.Millisecond), bucket, 1, opts)
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 0,
+		RetryIn:   0,
+		ResetIn:   3 * time.Millisecond,
+		Consumed:  1,
+		Balance:   0,
+	}, result)
+
+	_, _, err := Compute(now, Bucket{}, 1, Options{Burst: 1, Rate: 1, Period: 1, MinInterval: -1})
+	assert.Equal(t, ErrInvalidParameter, err)
+}
+
+func TestResolution(t *testing.T) {
+	opts := Options{
+		Burst:      3000,
+		Rate:       1,
+		Period:     30 * 24 * time.Hour,
+		Resolution: time.Millisecond,
+	}
+
+	bucket := MustGenerate(now, 0, opts)
+	assert.Equal(t, now.Add(3000*30*24*time.Hour), time.Time(bucket).UTC())
+
+	_, result := MustCompute(now, bucket, 0, opts)
+	assert.Equal(t, Result{
+		Limited:   true,
+		Remaining: 0,
+		RetryIn:   0,
+		ResetIn:   3000 * 30 * 24 * time.Hour,
+		Balance:   0,
+		Reason:    EmptyQuery,
+	}, result)
+
+	later := now.Add(30 * 24 * time.Hour)
+	_, result = MustCompute(later, bucket, 1, opts)
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 0,
+		RetryIn:   0,
+		ResetIn:   3000 * 30 * 24 * time.Hour,
+		Consumed:  1,
+		Balance:   0,
+	}, result)
+
+	bucket, result = MustCompute(now, Bucket{}, 1000, opts)
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 2000,
+		RetryIn:   0,
+		ResetIn:   1000 * 30 * 24 * time.Hour,
+		Consumed:  1000,
+		Balance:   2000,
+	}, result)
+
+	opts = Options{
+		Burst:      10,
+		Rate:       1,
+		Period:     2500 * time.Microsecond,
+		Resolution: time.Millisecond,
+	}
+
+	_, result = MustCompute(now.Add(400*time.Microsecond), Bucket{}, 1, opts)
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 9,
+		RetryIn:   0,
+		ResetIn:   3 * time.Millisecond,
+		Consumed:  1,
+		Balance:   9,
+	}, result)
+
+	opts.Resolution = time.Minute
+	opts.Period = time.Hour
+	bucket, result = MustCompute(now.Add(30*time.Second), Bucket{}, 2, opts)
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 8,
+		RetryIn:   0,
+		ResetIn:   2 * time.Hour,
+		Consumed:  2,
+		Balance:   8,
+	}, result)
+	assert.Equal(t, now.Truncate(time.Minute).Add(2*time.Hour), time.Time(bucket).UTC())
+
+	_, _, err := Compute(now, Bucket{}, 1, Options{Burst: 1, Rate: 1, Period: 1, Resolution: -1})
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, _, err = Compute(now, Bucket{}, 1, Options{Burst: 1, Rate: 1, Period: 1, Resolution: 3 * time.Millisecond / 7})
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, _, err = Compute(now, Bucket{}, 1, Options{Burst: 1, Rate: 1, Period: time.Millisecond, Resolution: time.Second})
+	assert.Equal(t, ErrZeroInterval, err)
+}
+
+func TestZeroInterval(t *testing.T) {
+	opts := Options{
+		Burst:  10,
+		Rate:   3,
+		Period: 1,
+	}
+
+	_, err := Generate(now, 1, opts)
+	assert.Equal(t, ErrZeroInterval, err)
+
+	_, _, err = Compute(now, Bucket{}, 1, opts)
+	assert.Equal(t, ErrZeroInterval, err)
+
+	_, _, err = Compute(now, Bucket{}, 1, Options{Burst: 10, Rate: 1e12, Period: time.Second})
+	assert.Equal(t, ErrZeroInterval, err)
+
+	opts.MinInterval = 1
+	_, _, err = Compute(now, Bucket{}, 1, opts)
+	assert.NoError(t, err)
+}
+
+func TestRoundDiv(t *testing.T) {
+	assert.Equal(t, int64(3), roundDiv(10, 3))
+	assert.Equal(t, int64(-3), roundDiv(-10, 3))
+	assert.Equal(t, int64(2), roundDiv(3, 2))
+	assert.Equal(t, int64(0), roundDiv(10, 0))
+	assert.Equal(t, int64(math.MaxInt64), roundDiv(math.MaxInt64, 1))
+	assert.Equal(t, int64(math.MinInt64), roundDiv(math.MinInt64, 1))
+	assert.Equal(t, int64(math.MaxInt64), roundDiv(math.MinInt64, -1))
+	assert.Equal(t, int64(-2), roundDiv(3, -2))
+	assert.Equal(t, int64(2), roundDiv(-3, -2))
+	assert.Equal(t, int64(1<<53+1), roundDiv(1<<54+2, 2))
+	assert.Equal(t, int64(math.MaxInt64/2+1), roundDiv(math.MaxInt64, 2))
+}
+
+func TestExplain(t *testing.T) {
+	e := Explain(int64(time.Second), 0, 4, 10, int64(10*time.Second), 2)
+	assert.Equal(t, Explanation{
+		EmissionInterval: int64(time.Second),
+		Increment:        int64(2 * time.Second),
+		BurstOffset:      int64(4 * time.Second),
+		TAT:              int64(time.Second),
+		NewTAT:           int64(3 * time.Second),
+		AllowAt:          int64(-time.Second),
+		Diff:             int64(time.Second),
+		Result:           int64(3 * time.Second),
+		Limited:          false,
+		Remaining:        1,
+		RetryIn:          0,
+		ResetIn:          int64(3 * time.Second),
+	}, e)
+
+	e = Explain(int64(3*time.Second), 0, 4, 10, int64(10*time.Second), 2)
+	assert.Equal(t, Explanation{
+		EmissionInterval: int64(time.Second),
+		Increment:        int64(2 * time.Second),
+		BurstOffset:      int64(4 * time.Second),
+		TAT:              int64(3 * time.Second),
+		NewTAT:           int64(5 * time.Second),
+		AllowAt:          int64(time.Second),
+		Diff:             int64(-time.Second),
+		Result:           int64(3 * time.Second),
+		Limited:          true,
+		Remaining:        1,
+		RetryIn:          int64(time.Second),
+		ResetIn:          int64(3 * time.Second),
+		Reason:           InsufficientTokens,
+	}, e)
+
+	for _, cost := range []int64{0, 1, 2, 3} {
+		for offset := int64(-20); offset <= 60; offset++ {
+			e := Explain(1000+offset, 1000, 3, 3, 10, cost)
+			tat, limited, remaining, retryIn, resetIn := ComputeRaw(1000+offset, 1000, 3, 3, 10, cost)
+			assert.Equal(t, tat, e.Result)
+			assert.Equal(t, limited, e.Limited)
+			assert.Equal(t, remaining, e.Remaining)
+			assert.Equal(t, retryIn, e.RetryIn)
+			assert.Equal(t, resetIn, e.ResetIn)
+		}
+	}
+}
+
+func BenchmarkCompute(b *testing.B) {
+	for _, item := range []struct {
+		name    string
+		opts    Options
+		bucket  func(Options) Bucket
+		cost    int64
+		step    time.Duration
+		limited bool
+	}{
+		{
+			name: "SmallBurstHighRate",
+			opts: Options{Burst: 1, Rate: 1e6, Period: time.Second},
+			cost: 1,
+			step: time.Microsecond,
+		},
+		{
+			name: "HugeBurstLongPeriod",
+			opts: Options{Burst: 1 << 40, Rate: 1, Period: 24 * time.Hour},
+			cost: 1,
+		},
+		{
+			name: "UnitCost",
+			opts: Options{Burst: 10, Rate: 10, Period: time.Second},
+			cost: 1,
+			step: 100 * time.Millisecond,
+		},
+		{
+			name: "BurstCost",
+			opts: Options{Burst: 10, Rate: 10, Period: time.Second},
+			cost: 10,
+			step: time.Second,
+		},
+		{
+			name: "Limited",
+			opts: Options{Burst: 10, Rate: 10, Period: time.Second},
+			bucket: func(opts Options) Bucket {
+				return MustGenerate(now, 0, opts)
+			},
+			cost:    1,
+			limited: true,
+		},
+		{
+			name: "Reset",
+			opts: Options{Burst: 10, Rate: 10, Period: time.Second},
+			cost: 1,
+			step: time.Minute,
+		},
+	} {
+		b.Run(item.name, func(b *testing.B) {
+			var bucket Bucket
+			if item.bucket != nil {
+				bucket = item.bucket(item.opts)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			var result Result
+			at := now
+			for i := 0; i < b.N; i++ {
+				at = at.Add(item.step)
+				bucket, result = MustCompute(at, bucket, item.cost, item.opts)
+			}
+
+			b.StopTimer()
+
+			if result.Limited != item.limited {
+				b.Fatalf("expected limited to be %t", item.limited)
+			}
+		})
 	}
 }
+
+func TestComputeRawUnit(t *testing.T) {
+	for _, burst := range []int64{1, 2, 3, 10} {
+		for _, rate := range []int64{1, 3, 7, 10, 1000} {
+			for _, period := range []int64{1, 10, 999, 1000} {
+				for offset := -period * 2; offset <= period*(burst+2); offset++ {
+					tat := 1000 + offset
+					newTAT1, limited1, remaining1, retryIn1, resetIn1 := ComputeRaw(tat, 1000, burst, rate, period, 1)
+					newTAT2, limited2, remaining2, retryIn2, resetIn2 := computeRaw(tat, 1000, burst, rate, period, 1)
+					assert.Equal(t, newTAT2, newTAT1)
+					assert.Equal(t, limited2, limited1)
+					assert.Equal(t, remaining2, remaining1)
+					assert.Equal(t, retryIn2, retryIn1)
+					assert.Equal(t, resetIn2, resetIn1)
+				}
+			}
+		}
+	}
+}
+
+func TestRemainingRaw(t *testing.T) {
+	assert.Equal(t, int64(4), RemainingRaw(0, 0, 4, 1, 10))
+	assert.Equal(t, int64(2), RemainingRaw(20, 0, 4, 1, 10))
+	assert.Equal(t, int64(0), RemainingRaw(100, 0, 4, 1, 10))
+	assert.Equal(t, int64(0), RemainingRaw(0, 0, 4, 0, 10))
+
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		burst := rnd.Int63n(100) + 1
+		rate := rnd.Int63n(10) + 1
+		period := rnd.Int63n(1000) + 1
+		now := rnd.Int63n(1e6)
+		tat := now + rnd.Int63n(2*burst*period+1) - burst*period
+		_, _, remaining, _, _ := ComputeRaw(tat, now, burst, rate, period, 0)
+		assert.Equal(t, remaining, RemainingRaw(tat, now, burst, rate, period), "tat=%d now=%d burst=%d rate=%d period=%d", tat, now, burst, rate, period)
+	}
+
+	// values near saturation agree
+	for i := 0; i < 10000; i++ {
+		burst := rnd.Int63n(math.MaxInt64/2) + 1
+		rate := rnd.Int63n(10) + 1
+		period := rnd.Int63n(1e12) + 1
+		now := rnd.Int63() - math.MaxInt64/2
+		tat := rnd.Int63() - math.MaxInt64/2
+		_, _, remaining, _, _ := ComputeRaw(tat, now, burst, rate, period, 0)
+		assert.Equal(t, remaining, RemainingRaw(tat, now, burst, rate, period), "tat=%d now=%d burst=%d rate=%d period=%d", tat, now, burst, rate, period)
+	}
+}
+
+func BenchmarkComputeRaw(b *testing.B) {
+	b.Run("Fast", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ComputeRaw(0, 0, 10, 10, int64(time.Second), 1)
+		}
+	})
+
+	b.Run("General", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			computeRaw(0, 0, 10, 10, int64(time.Second), 1)
+		}
+	})
+}
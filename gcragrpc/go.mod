@@ -0,0 +1,21 @@
+module github.com/256dpi/gcra/gcragrpc
+
+go 1.26.0
+
+require (
+	github.com/256dpi/gcra v0.1.0
+	github.com/stretchr/testify v1.7.0
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260921155816-b14227669459
+	google.golang.org/grpc v1.84.0
+	google.golang.org/protobuf v1.36.12
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.47.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+)
+
+// build against the parent module of this checkout
+replace github.com/256dpi/gcra => ../
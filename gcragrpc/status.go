@@ -0,0 +1,36 @@
+// Package gcragrpc provides helpers to use GCRA with gRPC. The package is a
+// separate module, so that the core module neither depends on gRPC nor on the
+// newer Go version required by gRPC.
+package gcragrpc
+
+import (
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/256dpi/gcra"
+)
+
+// StatusFromResult returns a ResourceExhausted status that carries a RetryInfo
+// detail with the retry delay of the provided result. It returns nil if the
+// result has not been limited.
+func StatusFromResult(r gcra.Result) *status.Status {
+	// check limited
+	if !r.Limited {
+		return nil
+	}
+
+	// create status
+	st := status.New(codes.ResourceExhausted, "rate limit exceeded")
+
+	// add retry info
+	withInfo, err := st.WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(r.RetryIn),
+	})
+	if err != nil {
+		return st
+	}
+
+	return withInfo
+}
@@ -0,0 +1,33 @@
+package gcragrpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+
+	"github.com/256dpi/gcra"
+)
+
+func TestStatusFromResult(t *testing.T) {
+	st := StatusFromResult(gcra.Result{
+		Remaining: 5,
+	})
+	assert.Nil(t, st)
+
+	st = StatusFromResult(gcra.Result{
+		Limited: true,
+		RetryIn: 1500 * time.Millisecond,
+	})
+	assert.NotNil(t, st)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+	assert.Equal(t, "rate limit exceeded", st.Message())
+
+	details := st.Details()
+	assert.Len(t, details, 1)
+	info, ok := details[0].(*errdetails.RetryInfo)
+	assert.True(t, ok)
+	assert.Equal(t, 1500*time.Millisecond, info.RetryDelay.AsDuration())
+}
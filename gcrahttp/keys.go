@@ -0,0 +1,108 @@
+// Package gcrahttp provides helpers to use GCRA with net/http.
+package gcrahttp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// KeyFunc derives a rate limit key from a request.
+type KeyFunc func(r *http.Request) string
+
+// ByIP returns a key func that uses the client IP as the key. The client IP is
+// read from the "X-Forwarded-For" header by skipping the specified number of
+// trusted proxies from the right. With zero trusted proxies the header is
+// ignored and the remote address is used. The remote address is also used if
+// the header has fewer entries than trusted proxies. Entries added by untrusted
+// clients are never used as long as the number of trusted proxies is correct.
+func ByIP(trustedProxies int) KeyFunc {
+	return func(r *http.Request) string {
+		// collect addresses
+		var addrs []string
+		if trustedProxies > 0 {
+			for _, value := range r.Header.Values("X-Forwarded-For") {
+				addrs = append(addrs, strings.Split(value, ",")...)
+			}
+		}
+		addrs = append(addrs, r.RemoteAddr)
+
+		// select address, a chain shorter than the trusted proxies has not
+		// passed all proxies and may have been spoofed by the client
+		index := len(addrs) - 1 - trustedProxies
+		if index < 0 {
+			return parseIP(r.RemoteAddr)
+		}
+
+		// parse address
+		ip := parseIP(addrs[index])
+		if ip == "" {
+			ip = parseIP(r.RemoteAddr)
+		}
+
+		return ip
+	}
+}
+
+// ByHeader returns a key func that uses the value of the specified header as
+// the key.
+func ByHeader(name string) KeyFunc {
+	return func(r *http.Request) string {
+		return r.Header.Get(name)
+	}
+}
+
+// ByAuthToken returns a key func that uses the token from the "Authorization"
+// header as the key. A "Bearer" prefix is removed and the token is hashed so
+// that the key does not expose the credential. Requests without a token yield
+// an empty key.
+func ByAuthToken() KeyFunc {
+	return func(r *http.Request) string {
+		// get token
+		token := strings.TrimSpace(r.Header.Get("Authorization"))
+		if len(token) > 7 && strings.EqualFold(token[:7], "bearer ") {
+			token = strings.TrimSpace(token[7:])
+		}
+		if token == "" {
+			return ""
+		}
+
+		// hash token
+		sum := sha256.Sum256([]byte(token))
+
+		return hex.EncodeToString(sum[:])
+	}
+}
+
+// Combine returns a key func that concatenates the keys of the provided key
+// funcs separated by "|".
+func Combine(funcs ...KeyFunc) KeyFunc {
+	return func(r *http.Request) string {
+		keys := make([]string, 0, len(funcs))
+		for _, fn := range funcs {
+			keys = append(keys, fn(r))
+		}
+		return strings.Join(keys, "|")
+	}
+}
+
+func parseIP(addr string) string {
+	// remove port
+	addr = strings.TrimSpace(addr)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+
+	// remove brackets
+	addr = strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+
+	// parse IP
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return ""
+	}
+
+	return ip.String()
+}
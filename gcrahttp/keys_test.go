@@ -0,0 +1,98 @@
+package gcrahttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func request(remoteAddr string, headers ...string) *http.Request {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = remoteAddr
+	for i := 0; i < len(headers); i += 2 {
+		req.Header.Add(headers[i], headers[i+1])
+	}
+	return req
+}
+
+func TestByIP(t *testing.T) {
+	key := ByIP(0)(request("1.2.3.4:1234"))
+	assert.Equal(t, "1.2.3.4", key)
+
+	key = ByIP(0)(request("1.2.3.4:1234", "X-Forwarded-For", "5.6.7.8"))
+	assert.Equal(t, "1.2.3.4", key)
+
+	key = ByIP(1)(request("10.0.0.1:1234", "X-Forwarded-For", "5.6.7.8"))
+	assert.Equal(t, "5.6.7.8", key)
+
+	key = ByIP(1)(request("10.0.0.1:1234"))
+	assert.Equal(t, "10.0.0.1", key)
+
+	key = ByIP(2)(request("10.0.0.1:1234", "X-Forwarded-For", "5.6.7.8, 10.0.0.2"))
+	assert.Equal(t, "5.6.7.8", key)
+
+	key = ByIP(2)(request("10.0.0.1:1234", "X-Forwarded-For", "5.6.7.8", "X-Forwarded-For", "10.0.0.2"))
+	assert.Equal(t, "5.6.7.8", key)
+
+	key = ByIP(5)(request("10.0.0.1:1234", "X-Forwarded-For", "5.6.7.8, 10.0.0.2"))
+	assert.Equal(t, "10.0.0.1", key)
+}
+
+func TestByIPSpoofed(t *testing.T) {
+	key := ByIP(1)(request("10.0.0.1:1234", "X-Forwarded-For", "6.6.6.6, 5.6.7.8"))
+	assert.Equal(t, "5.6.7.8", key)
+
+	key = ByIP(2)(request("10.0.0.1:1234", "X-Forwarded-For", "6.6.6.6, 7.7.7.7, 5.6.7.8, 10.0.0.2"))
+	assert.Equal(t, "5.6.7.8", key)
+
+	key = ByIP(1)(request("10.0.0.1:1234", "X-Forwarded-For", "5.6.7.8, garbage"))
+	assert.Equal(t, "10.0.0.1", key)
+
+	key = ByIP(3)(request("10.0.0.1:1234", "X-Forwarded-For", "6.6.6.6, 10.0.0.2"))
+	assert.Equal(t, "10.0.0.1", key)
+}
+
+func TestByIPv6(t *testing.T) {
+	key := ByIP(0)(request("[2001:db8::1]:1234"))
+	assert.Equal(t, "2001:db8::1", key)
+
+	key = ByIP(1)(request("10.0.0.1:1234", "X-Forwarded-For", "2001:0db8:0000::0002"))
+	assert.Equal(t, "2001:db8::2", key)
+
+	key = ByIP(1)(request("10.0.0.1:1234", "X-Forwarded-For", "[2001:db8::3]:4321"))
+	assert.Equal(t, "2001:db8::3", key)
+
+	key = ByIP(1)(request("[::1]:1234", "X-Forwarded-For", "1.2.3.4, ::ffff:5.6.7.8"))
+	assert.Equal(t, "5.6.7.8", key)
+}
+
+func TestByHeader(t *testing.T) {
+	key := ByHeader("X-API-Key")(request("1.2.3.4:1234", "X-API-Key", "foo"))
+	assert.Equal(t, "foo", key)
+
+	key = ByHeader("X-API-Key")(request("1.2.3.4:1234"))
+	assert.Equal(t, "", key)
+}
+
+func TestByAuthToken(t *testing.T) {
+	key1 := ByAuthToken()(request("1.2.3.4:1234", "Authorization", "Bearer secret"))
+	key2 := ByAuthToken()(request("1.2.3.4:1234", "Authorization", "secret"))
+	key3 := ByAuthToken()(request("1.2.3.4:1234", "Authorization", "Bearer other"))
+	assert.Len(t, key1, 64)
+	assert.Equal(t, key1, key2)
+	assert.NotEqual(t, key1, key3)
+	assert.NotContains(t, key1, "secret")
+
+	key := ByAuthToken()(request("1.2.3.4:1234"))
+	assert.Equal(t, "", key)
+}
+
+func TestCombine(t *testing.T) {
+	key := Combine(ByIP(0), ByHeader("X-API-Key"))(request("1.2.3.4:1234", "X-API-Key", "foo"))
+	assert.Equal(t, "1.2.3.4|foo", key)
+
+	key = Combine()(request("1.2.3.4:1234"))
+	assert.Equal(t, "", key)
+}
@@ -0,0 +1,189 @@
+// Package gcrarate provides a GCRA based limiter with an API similar to
+// golang.org/x/time/rate.
+package gcrarate
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/256dpi/gcra"
+)
+
+// Limiter limits events using a single GCRA bucket. It is safe for concurrent
+// use.
+type Limiter struct {
+	opts   gcra.Options
+	mutex  sync.Mutex
+	bucket gcra.Bucket
+}
+
+// NewLimiter creates and returns a new limiter with a full bucket.
+func NewLimiter(opts gcra.Options) *Limiter {
+	return &Limiter{
+		opts: opts,
+	}
+}
+
+// Options returns the options of the limiter.
+func (l *Limiter) Options() gcra.Options {
+	return l.opts
+}
+
+// Allow is shorthand for AllowN(time.Now(), 1).
+func (l *Limiter) Allow() bool {
+	return l.AllowN(time.Now(), 1)
+}
+
+// AllowN reports whether n events may happen at the specified time and
+// consumes n tokens if so.
+func (l *Limiter) AllowN(now time.Time, n int) bool {
+	// acquire mutex
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// compute
+	bucket, result, err := gcra.Compute(now, l.bucket, int64(n), l.opts)
+	if err != nil {
+		return false
+	}
+
+	// update bucket, limited computations return the unchanged but initialized
+	// bucket so that the warm up is only applied once
+	l.bucket = bucket
+
+	return !result.Limited
+}
+
+// Reservation holds tokens reserved by the limiter.
+type Reservation struct {
+	ok          bool
+	limiter     *Limiter
+	at          time.Time
+	reservation gcra.Reservation
+}
+
+// OK reports whether the tokens have been reserved. Reservations fail if the
+// amount of tokens exceeds the burst.
+func (r *Reservation) OK() bool {
+	return r.ok
+}
+
+// Delay is shorthand for DelayFrom(time.Now()).
+func (r *Reservation) Delay() time.Duration {
+	return r.DelayFrom(time.Now())
+}
+
+// DelayFrom returns the duration the caller must wait from the specified time
+// before the reserved tokens may be used.
+func (r *Reservation) DelayFrom(now time.Time) time.Duration {
+	// check ok
+	if !r.ok {
+		return time.Duration(math.MaxInt64)
+	}
+
+	// compute delay
+	delay := r.at.Add(r.reservation.Delay).Sub(now)
+	if delay < 0 {
+		return 0
+	}
+
+	return delay
+}
+
+// Cancel is shorthand for CancelAt(time.Now()).
+func (r *Reservation) Cancel() {
+	r.CancelAt(time.Now())
+}
+
+// CancelAt refunds the reserved tokens if they have not yet been used.
+func (r *Reservation) CancelAt(now time.Time) {
+	// check ok
+	if !r.ok {
+		return
+	}
+
+	// acquire mutex
+	r.limiter.mutex.Lock()
+	defer r.limiter.mutex.Unlock()
+
+	// refund tokens
+	r.limiter.bucket = r.reservation.Cancel(now, r.limiter.bucket)
+	r.ok = false
+}
+
+// Reserve is shorthand for ReserveN(time.Now(), 1).
+func (l *Limiter) Reserve() *Reservation {
+	return l.ReserveN(time.Now(), 1)
+}
+
+// ReserveN reserves n tokens at the specified time. Unlike AllowN, the tokens
+// are always reserved if the reservation is OK and the caller must wait for
+// the reported delay before using them.
+func (l *Limiter) ReserveN(now time.Time, n int) *Reservation {
+	// acquire mutex
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// reserve
+	reservation, bucket, err := gcra.Reserve(now, l.bucket, int64(n), l.opts)
+	if err != nil {
+		return &Reservation{}
+	}
+
+	// update bucket
+	l.bucket = bucket
+
+	return &Reservation{
+		ok:          true,
+		limiter:     l,
+		at:          now,
+		reservation: reservation,
+	}
+}
+
+// Wait is shorthand for WaitN(ctx, 1).
+func (l *Limiter) Wait(ctx context.Context) error {
+	return l.WaitN(ctx, 1)
+}
+
+// WaitN blocks until n tokens are available. It returns an error if n exceeds
+// the burst, the context is cancelled or the deadline would be exceeded.
+func (l *Limiter) WaitN(ctx context.Context, n int) error {
+	// check context
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// reserve tokens
+	now := time.Now()
+	r := l.ReserveN(now, n)
+	if !r.OK() {
+		return errors.New("gcrarate: cannot reserve tokens")
+	}
+
+	// check delay
+	delay := r.DelayFrom(now)
+	if delay == 0 {
+		return nil
+	}
+
+	// check deadline
+	if deadline, ok := ctx.Deadline(); ok && now.Add(delay).After(deadline) {
+		r.CancelAt(now)
+		return errors.New("gcrarate: wait would exceed context deadline")
+	}
+
+	// wait
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		r.Cancel()
+		return ctx.Err()
+	}
+}
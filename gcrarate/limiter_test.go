@@ -0,0 +1,91 @@
+package gcrarate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/256dpi/gcra"
+)
+
+var now = time.Date(2022, 1, 23, 10, 52, 0, 0, time.UTC)
+
+func TestLimiterAllowN(t *testing.T) {
+	l := NewLimiter(gcra.Options{Burst: 5, Rate: 10, Period: time.Second})
+
+	assert.True(t, l.AllowN(now, 3))
+	assert.True(t, l.AllowN(now, 2))
+	assert.False(t, l.AllowN(now, 1))
+	assert.False(t, l.AllowN(now, 6))
+
+	assert.True(t, l.AllowN(now.Add(100*time.Millisecond), 1))
+	assert.False(t, l.AllowN(now.Add(100*time.Millisecond), 1))
+
+	assert.True(t, l.AllowN(now.Add(time.Second), 5))
+}
+
+func TestLimiterAllow(t *testing.T) {
+	l := NewLimiter(gcra.Options{Burst: 2, Rate: 1, Period: time.Hour})
+
+	assert.True(t, l.Allow())
+	assert.True(t, l.Allow())
+	assert.False(t, l.Allow())
+}
+
+func TestLimiterReserveN(t *testing.T) {
+	l := NewLimiter(gcra.Options{Burst: 2, Rate: 10, Period: time.Second})
+
+	r := l.ReserveN(now, 2)
+	assert.True(t, r.OK())
+	assert.Equal(t, time.Duration(0), r.DelayFrom(now))
+
+	r = l.ReserveN(now, 1)
+	assert.True(t, r.OK())
+	assert.Equal(t, 100*time.Millisecond, r.DelayFrom(now))
+	assert.Equal(t, 50*time.Millisecond, r.DelayFrom(now.Add(50*time.Millisecond)))
+	assert.False(t, l.AllowN(now.Add(100*time.Millisecond), 1))
+
+	r.CancelAt(now)
+	assert.True(t, l.AllowN(now.Add(100*time.Millisecond), 1))
+
+	r = l.ReserveN(now, 3)
+	assert.False(t, r.OK())
+	r.CancelAt(now)
+}
+
+func TestLimiterWait(t *testing.T) {
+	l := NewLimiter(gcra.Options{Burst: 1, Rate: 1, Period: 20 * time.Millisecond})
+
+	start := time.Now()
+	assert.NoError(t, l.Wait(context.Background()))
+	assert.NoError(t, l.Wait(context.Background()))
+	assert.True(t, time.Since(start) >= 15*time.Millisecond)
+
+	err := l.WaitN(context.Background(), 2)
+	assert.Error(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	err = l.Wait(ctx)
+	assert.Error(t, err)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	err = l.Wait(ctx)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestLimiterAllowNWarmUp(t *testing.T) {
+	l := NewLimiter(gcra.Options{Burst: 10, Rate: 10, Period: time.Second, WarmUp: time.Second})
+
+	// the warm up is only applied to the first request
+	var admitted int
+	for i := 0; i < 100; i++ {
+		if l.AllowN(now.Add(time.Duration(i)*100*time.Millisecond), 1) {
+			admitted++
+		}
+	}
+	assert.Equal(t, 99, admitted)
+}
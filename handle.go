@@ -0,0 +1,15 @@
+package gcra
+
+// ComputeHandle is a flat variant of ComputeRaw that only uses int64 values
+// and is suitable for FFI bindings. The limited flag is reported as one if the
+// request has been limited and zero otherwise. All times and durations are in
+// nanoseconds.
+func ComputeHandle(tatNanos, nowNanos, burst, rate, periodNanos, cost int64) (newTatNanos, limited, remaining, retryInNanos, resetInNanos int64) {
+	// compute GCRA
+	newTAT, isLimited, remaining, retryIn, resetIn := ComputeRaw(tatNanos, nowNanos, burst, rate, periodNanos, cost)
+	if isLimited {
+		limited = 1
+	}
+
+	return newTAT, limited, remaining, retryIn, resetIn
+}
@@ -0,0 +1,32 @@
+package gcra
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeHandle(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		burst := rnd.Int63n(100) + 1
+		rate := rnd.Int63n(10) + 1
+		period := rnd.Int63n(1000) + 1
+		cost := rnd.Int63n(burst + 1)
+		now := rnd.Int63n(1e6)
+		tat := now + rnd.Int63n(2*burst*period+1) - burst*period
+
+		newTAT1, limited1, remaining1, retryIn1, resetIn1 := ComputeRaw(tat, now, burst, rate, period, cost)
+		newTAT2, limited2, remaining2, retryIn2, resetIn2 := ComputeHandle(tat, now, burst, rate, period, cost)
+		assert.Equal(t, newTAT1, newTAT2)
+		if limited1 {
+			assert.Equal(t, int64(1), limited2)
+		} else {
+			assert.Equal(t, int64(0), limited2)
+		}
+		assert.Equal(t, remaining1, remaining2)
+		assert.Equal(t, retryIn1, retryIn2)
+		assert.Equal(t, resetIn1, resetIn2)
+	}
+}
@@ -0,0 +1,51 @@
+package gcra
+
+import (
+	"strconv"
+	"time"
+)
+
+// Headers returns the canonical rate limit headers for the result. The limit is
+// reported as the burst available to regular requests, i.e. excluding the
+// reserve and the exclusive token, and the reset as whole seconds. The
+// "Retry-After" header is only included if the request has been limited.
+// Durations are rounded up so that clients never retry too early. Unlimited
+// options yield no headers.
+func (r Result) Headers(opts Options) map[string]string {
+	// handle unlimited
+	if opts.Burst == Unlimited {
+		return map[string]string{}
+	}
+
+	// prepare headers
+	headers := map[string]string{
+		"X-RateLimit-Limit":     strconv.FormatInt(opts.burst(false), 10),
+		"X-RateLimit-Remaining": strconv.FormatInt(r.Remaining, 10),
+		"X-RateLimit-Reset":     strconv.FormatInt(ceilSeconds(r.ResetIn), 10),
+	}
+
+	// add retry after if limited
+	if r.Limited {
+		headers["Retry-After"] = strconv.Itoa(r.RetryAfterSeconds())
+	}
+
+	return headers
+}
+
+// RetryAfterSeconds returns the retry duration rounded up to whole seconds as
+// required by the "Retry-After" header. It returns zero if the request has not
+// been limited.
+func (r Result) RetryAfterSeconds() int {
+	if !r.Limited {
+		return 0
+	}
+	return int(ceilSeconds(r.RetryIn))
+}
+
+func ceilSeconds(d time.Duration) int64 {
+	secs := int64(d / time.Second)
+	if d%time.Second > 0 {
+		secs++
+	}
+	return secs
+}
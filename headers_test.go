@@ -0,0 +1,75 @@
+package gcra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultHeaders(t *testing.T) {
+	opts := Options{
+		Burst:  50,
+		Rate:   10,
+		Period: time.Second,
+	}
+
+	headers := Result{
+		Limited:   false,
+		Remaining: 15,
+		ResetIn:   3500 * time.Millisecond,
+	}.Headers(opts)
+	assert.Equal(t, map[string]string{
+		"X-RateLimit-Limit":     "50",
+		"X-RateLimit-Remaining": "15",
+		"X-RateLimit-Reset":     "4",
+	}, headers)
+
+	headers = Result{
+		Limited:   true,
+		Remaining: 0,
+		RetryIn:   1500 * time.Millisecond,
+		ResetIn:   5 * time.Second,
+	}.Headers(opts)
+	assert.Equal(t, map[string]string{
+		"X-RateLimit-Limit":     "50",
+		"X-RateLimit-Remaining": "0",
+		"X-RateLimit-Reset":     "5",
+		"Retry-After":           "2",
+	}, headers)
+
+	headers = Result{
+		Limited:   true,
+		Remaining: 0,
+		RetryIn:   0,
+		ResetIn:   5 * time.Second,
+	}.Headers(opts)
+	assert.Equal(t, "0", headers["Retry-After"])
+
+	// the reserve and exclusive token are not available to regular requests
+	opts.Reserve = 5
+	opts.Exclusive = true
+	_, result := MustCompute(now, Bucket{}, 1, opts)
+	headers = result.Headers(opts)
+	assert.Equal(t, map[string]string{
+		"X-RateLimit-Limit":     "44",
+		"X-RateLimit-Remaining": "43",
+		"X-RateLimit-Reset":     "1",
+	}, headers)
+
+	// unlimited options yield no headers
+	opts = Options{Burst: Unlimited, Rate: 1, Period: time.Second}
+	_, result = MustCompute(now, Bucket{}, 1, opts)
+	assert.Empty(t, result.Headers(opts))
+}
+
+func TestResultRetryAfterSeconds(t *testing.T) {
+	assert.Equal(t, 0, Result{}.RetryAfterSeconds())
+	assert.Equal(t, 0, Result{Limited: false, RetryIn: time.Second}.RetryAfterSeconds())
+	assert.Equal(t, 0, Result{Limited: true, RetryIn: 0}.RetryAfterSeconds())
+	assert.Equal(t, 1, Result{Limited: true, RetryIn: time.Nanosecond}.RetryAfterSeconds())
+	assert.Equal(t, 1, Result{Limited: true, RetryIn: 500 * time.Millisecond}.RetryAfterSeconds())
+	assert.Equal(t, 1, Result{Limited: true, RetryIn: time.Second}.RetryAfterSeconds())
+	assert.Equal(t, 2, Result{Limited: true, RetryIn: 1500 * time.Millisecond}.RetryAfterSeconds())
+	assert.Equal(t, 3, Result{Limited: true, RetryIn: 3 * time.Second}.RetryAfterSeconds())
+}
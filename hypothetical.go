@@ -0,0 +1,41 @@
+package gcra
+
+import "time"
+
+// ComputeWith will compute the result of the request under hypothetical
+// options without affecting the bucket. The bucket is interpreted according to
+// the actual options and converted to the hypothetical options by preserving
+// the fraction of capacity consumed.
+func ComputeWith(now time.Time, bucket Bucket, cost int64, actual, hypothetical Options) (Result, error) {
+	// check actual options
+	_, _, err := Compute(now, bucket, 0, actual)
+	if err != nil {
+		return Result{}, err
+	}
+
+	// compute consumed fraction
+	var consumed float64
+	if actual.Burst != Unlimited {
+		offset := time.Time(actual.initial(now, bucket)).Sub(now)
+		if offset > 0 {
+			consumed = float64(offset) / float64(actual.Window())
+		}
+		if consumed > 1 {
+			consumed = 1
+		}
+	}
+
+	// convert bucket
+	if hypothetical.Burst != Unlimited {
+		offset := time.Duration(consumed * float64(hypothetical.Window()))
+		bucket = Bucket(time.Unix(0, now.UnixNano()+int64(offset)))
+	}
+
+	// compute result
+	_, result, err := Compute(now, bucket, cost, hypothetical)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return result, nil
+}
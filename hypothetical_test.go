@@ -0,0 +1,100 @@
+package gcra
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeWith(t *testing.T) {
+	actual := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+	}
+
+	bucket := MustGenerate(now, 6, actual)
+
+	result, err := ComputeWith(now, bucket, 1, actual, actual)
+	assert.NoError(t, err)
+	_, expected := MustCompute(now, bucket, 1, actual)
+	assert.Equal(t, expected, result)
+
+	stricter := Options{
+		Burst:  5,
+		Rate:   1,
+		Period: time.Second,
+	}
+
+	result, err = ComputeWith(now, bucket, 1, actual, stricter)
+	assert.NoError(t, err)
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 2,
+		RetryIn:   0,
+		ResetIn:   3 * time.Second,
+		Consumed:  1,
+		Balance:   2,
+	}, result)
+
+	result, err = ComputeWith(now, bucket, 4, actual, stricter)
+	assert.NoError(t, err)
+	assert.Equal(t, Result{
+		Limited:   true,
+		Remaining: 3,
+		RetryIn:   time.Second,
+		ResetIn:   2 * time.Second,
+		Balance:   3,
+		Reason:    InsufficientTokens,
+	}, result)
+
+	lenient := Options{
+		Burst:  100,
+		Rate:   100,
+		Period: time.Second,
+	}
+
+	result, err = ComputeWith(now, bucket, 50, actual, lenient)
+	assert.NoError(t, err)
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 10,
+		RetryIn:   0,
+		ResetIn:   900 * time.Millisecond,
+		Consumed:  50,
+		Balance:   10,
+	}, result)
+
+	_, after := MustCompute(now, bucket, 0, actual)
+	assert.Equal(t, int64(6), after.Remaining)
+}
+
+func TestComputeWithStale(t *testing.T) {
+	actual := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+	}
+
+	result, err := ComputeWith(now, Bucket{}, 0, actual, Options{Burst: 3, Rate: 1, Period: time.Second})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), result.Remaining)
+
+	result, err = ComputeWith(now, Bucket(now.Add(time.Hour)), 0, actual, Options{Burst: 3, Rate: 1, Period: time.Second})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), result.Remaining)
+
+	result, err = ComputeWith(now, Bucket(now.Add(time.Hour)), 1, actual, Options{Burst: Unlimited, Rate: 1, Period: time.Second})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(math.MaxInt64), result.Remaining)
+}
+
+func TestComputeWithErrors(t *testing.T) {
+	_, err := ComputeWith(now, Bucket{}, 0, Options{}, Options{Burst: 1, Rate: 1, Period: 1})
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, err = ComputeWith(now, Bucket{}, 2, Options{Burst: 1, Rate: 1, Period: 1}, Options{Burst: 1, Rate: 1, Period: 1})
+	assert.Equal(t, ErrCostHigherThanBurst, err)
+}
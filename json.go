@@ -0,0 +1,184 @@
+package gcra
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+type resultJSON struct {
+	Limited   bool    `json:"limited"`
+	Remaining int64   `json:"remaining"`
+	RetryIn   float64 `json:"retry_in"`
+	ResetIn   float64 `json:"reset_in"`
+	Consumed  int64   `json:"consumed"`
+	Balance   int64   `json:"balance"`
+	Reason    string  `json:"reason"`
+}
+
+// MarshalJSON implements the json.Marshaler interface. The fields use snake
+// case names like "retry_in", durations are rendered as float seconds and the
+// reason as its name.
+func (r Result) MarshalJSON() ([]byte, error) {
+	return json.Marshal(resultJSON{
+		Limited:   r.Limited,
+		Remaining: r.Remaining,
+		RetryIn:   r.RetryIn.Seconds(),
+		ResetIn:   r.ResetIn.Seconds(),
+		Consumed:  r.Consumed,
+		Balance:   r.Balance,
+		Reason:    r.Reason.String(),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It reads the format
+// written by MarshalJSON and rounds durations to the nearest nanosecond.
+func (r *Result) UnmarshalJSON(data []byte) error {
+	// decode data
+	var res resultJSON
+	err := json.Unmarshal(data, &res)
+	if err != nil {
+		return err
+	}
+
+	// parse reason
+	var reason Reason
+	err = reason.UnmarshalText([]byte(res.Reason))
+	if err != nil {
+		return err
+	}
+
+	*r = Result{
+		Limited:   res.Limited,
+		Remaining: res.Remaining,
+		RetryIn:   fromSeconds(res.RetryIn),
+		ResetIn:   fromSeconds(res.ResetIn),
+		Consumed:  res.Consumed,
+		Balance:   res.Balance,
+		Reason:    reason,
+	}
+
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface. The TAT is rendered as
+// an RFC 3339 timestamp with nanoseconds like time.Time.
+func (b Bucket) MarshalJSON() ([]byte, error) {
+	return time.Time(b).MarshalJSON()
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It reads the format
+// written by MarshalJSON.
+func (b *Bucket) UnmarshalJSON(data []byte) error {
+	return (*time.Time)(b).UnmarshalJSON(data)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface. The reason is
+// rendered as its name.
+func (r Reason) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. It reads
+// the names written by MarshalText, an empty name yields NotLimited.
+func (r *Reason) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "", NotLimited.String():
+		*r = NotLimited
+	case InsufficientTokens.String():
+		*r = InsufficientTokens
+	case EmptyQuery.String():
+		*r = EmptyQuery
+	case EarlyRejection.String():
+		*r = EarlyRejection
+	default:
+		return fmt.Errorf("unknown reason %q", text)
+	}
+	return nil
+}
+
+type optionsJSON struct {
+	Burst               int64   `json:"burst"`
+	Rate                int64   `json:"rate"`
+	Period              float64 `json:"period"`
+	Exclusive           bool    `json:"exclusive,omitempty"`
+	MinInterval         float64 `json:"min_interval,omitempty"`
+	TokenBytes          int64   `json:"token_bytes,omitempty"`
+	Resolution          float64 `json:"resolution,omitempty"`
+	Reserve             int64   `json:"reserve,omitempty"`
+	AllowOverdraft      int64   `json:"allow_overdraft,omitempty"`
+	EarlyRejectAt       int64   `json:"early_reject_at,omitempty"`
+	NoReset             bool    `json:"no_reset,omitempty"`
+	ClampDurations      bool    `json:"clamp_durations,omitempty"`
+	RejectBackwardsTime bool    `json:"reject_backwards_time,omitempty"`
+	ClockSkewTolerance  float64 `json:"clock_skew_tolerance,omitempty"`
+	BaseCost            int64   `json:"base_cost,omitempty"`
+	WarmUp              float64 `json:"warm_up,omitempty"`
+	SustainedRate       int64   `json:"sustained_rate,omitempty"`
+	SustainedPeriod     float64 `json:"sustained_period,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface. Like Result, the fields
+// use snake case names and durations are rendered as float seconds. Unset
+// modifiers are omitted.
+func (o Options) MarshalJSON() ([]byte, error) {
+	return json.Marshal(optionsJSON{
+		Burst:               o.Burst,
+		Rate:                o.Rate,
+		Period:              o.Period.Seconds(),
+		Exclusive:           o.Exclusive,
+		MinInterval:         o.MinInterval.Seconds(),
+		TokenBytes:          o.TokenBytes,
+		Resolution:          o.Resolution.Seconds(),
+		Reserve:             o.Reserve,
+		AllowOverdraft:      o.AllowOverdraft,
+		EarlyRejectAt:       o.EarlyRejectAt,
+		NoReset:             o.NoReset,
+		ClampDurations:      o.ClampDurations,
+		RejectBackwardsTime: o.RejectBackwardsTime,
+		ClockSkewTolerance:  o.ClockSkewTolerance.Seconds(),
+		BaseCost:            o.BaseCost,
+		WarmUp:              o.WarmUp.Seconds(),
+		SustainedRate:       o.SustainedRate,
+		SustainedPeriod:     o.SustainedPeriod.Seconds(),
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It reads the format
+// written by MarshalJSON and rounds durations to the nearest nanosecond.
+func (o *Options) UnmarshalJSON(data []byte) error {
+	// decode data
+	var opts optionsJSON
+	err := json.Unmarshal(data, &opts)
+	if err != nil {
+		return err
+	}
+
+	*o = Options{
+		Burst:               opts.Burst,
+		Rate:                opts.Rate,
+		Period:              fromSeconds(opts.Period),
+		Exclusive:           opts.Exclusive,
+		MinInterval:         fromSeconds(opts.MinInterval),
+		TokenBytes:          opts.TokenBytes,
+		Resolution:          fromSeconds(opts.Resolution),
+		Reserve:             opts.Reserve,
+		AllowOverdraft:      opts.AllowOverdraft,
+		EarlyRejectAt:       opts.EarlyRejectAt,
+		NoReset:             opts.NoReset,
+		ClampDurations:      opts.ClampDurations,
+		RejectBackwardsTime: opts.RejectBackwardsTime,
+		ClockSkewTolerance:  fromSeconds(opts.ClockSkewTolerance),
+		BaseCost:            opts.BaseCost,
+		WarmUp:              fromSeconds(opts.WarmUp),
+		SustainedRate:       opts.SustainedRate,
+		SustainedPeriod:     fromSeconds(opts.SustainedPeriod),
+	}
+
+	return nil
+}
+
+func fromSeconds(secs float64) time.Duration {
+	return time.Duration(math.Round(secs * float64(time.Second)))
+}
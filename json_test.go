@@ -0,0 +1,87 @@
+package gcra
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultJSON(t *testing.T) {
+	limited := Result{
+		Limited:   true,
+		Remaining: 0,
+		RetryIn:   1500 * time.Millisecond,
+		ResetIn:   3 * time.Second,
+		Balance:   0,
+		Reason:    InsufficientTokens,
+	}
+
+	buf, err := json.Marshal(limited)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"limited":true,"remaining":0,"retry_in":1.5,"reset_in":3,"consumed":0,"balance":0,"reason":"InsufficientTokens"}`, string(buf))
+
+	var result Result
+	err = json.Unmarshal(buf, &result)
+	assert.NoError(t, err)
+	assert.Equal(t, limited, result)
+
+	allowed := Result{
+		Remaining: 4,
+		ResetIn:   time.Nanosecond,
+		Consumed:  1,
+		Balance:   4,
+	}
+
+	buf, err = json.Marshal(allowed)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"limited":false,"remaining":4,"retry_in":0,"reset_in":1e-9,"consumed":1,"balance":4,"reason":"NotLimited"}`, string(buf))
+
+	err = json.Unmarshal(buf, &result)
+	assert.NoError(t, err)
+	assert.Equal(t, allowed, result)
+
+	buf, err = json.Marshal(Result{})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"limited":false,"remaining":0,"retry_in":0,"reset_in":0,"consumed":0,"balance":0,"reason":"NotLimited"}`, string(buf))
+
+	err = json.Unmarshal([]byte(`{"reason":"Foo"}`), &result)
+	assert.Error(t, err)
+}
+
+func TestOptionsJSON(t *testing.T) {
+	opts := Options{
+		Burst:               10,
+		Rate:                5,
+		Period:              1500 * time.Millisecond,
+		Exclusive:           true,
+		MinInterval:         time.Millisecond,
+		TokenBytes:          1024,
+		Resolution:          time.Microsecond,
+		Reserve:             2,
+		AllowOverdraft:      3,
+		EarlyRejectAt:       4,
+		NoReset:             true,
+		ClampDurations:      true,
+		RejectBackwardsTime: true,
+		ClockSkewTolerance:  250 * time.Millisecond,
+		BaseCost:            1,
+		WarmUp:              2 * time.Second,
+		SustainedRate:       100,
+		SustainedPeriod:     time.Hour,
+	}
+
+	buf, err := json.Marshal(opts)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"burst":10,"rate":5,"period":1.5,"exclusive":true,"min_interval":0.001,"token_bytes":1024,"resolution":0.000001,"reserve":2,"allow_overdraft":3,"early_reject_at":4,"no_reset":true,"clamp_durations":true,"reject_backwards_time":true,"clock_skew_tolerance":0.25,"base_cost":1,"warm_up":2,"sustained_rate":100,"sustained_period":3600}`, string(buf))
+
+	var decoded Options
+	err = json.Unmarshal(buf, &decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, opts, decoded)
+
+	buf, err = json.Marshal(Options{Burst: 10, Rate: 10, Period: time.Second})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"burst":10,"rate":10,"period":1}`, string(buf))
+}
@@ -0,0 +1,37 @@
+package gcra
+
+import "time"
+
+// ResultMillis is a representation of a result that uses integer milliseconds
+// instead of time.Duration values for clients that are not written in Go. Like
+// Result, it is encoded as JSON using snake case names and the reason name.
+type ResultMillis struct {
+	Limited   bool   `json:"limited"`
+	Remaining int64  `json:"remaining"`
+	RetryIn   int64  `json:"retry_in_ms"`
+	ResetIn   int64  `json:"reset_in_ms"`
+	Consumed  int64  `json:"consumed"`
+	Balance   int64  `json:"balance"`
+	Reason    Reason `json:"reason"`
+}
+
+// ToMillis will convert the result to its millisecond representation. RetryIn
+// is rounded up so that clients never retry early while ResetIn is rounded to
+// the nearest millisecond.
+func (r Result) ToMillis() ResultMillis {
+	// round up retry
+	retryIn := int64(r.RetryIn / time.Millisecond)
+	if r.RetryIn%time.Millisecond > 0 {
+		retryIn++
+	}
+
+	return ResultMillis{
+		Limited:   r.Limited,
+		Remaining: r.Remaining,
+		RetryIn:   retryIn,
+		ResetIn:   int64(r.ResetIn.Round(time.Millisecond) / time.Millisecond),
+		Consumed:  r.Consumed,
+		Balance:   r.Balance,
+		Reason:    r.Reason,
+	}
+}
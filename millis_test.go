@@ -0,0 +1,57 @@
+package gcra
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultToMillis(t *testing.T) {
+	millis := Result{
+		Limited:   true,
+		Remaining: 2,
+		RetryIn:   1500 * time.Millisecond,
+		ResetIn:   3 * time.Second,
+		Balance:   2,
+		Reason:    InsufficientTokens,
+	}.ToMillis()
+	assert.Equal(t, ResultMillis{
+		Limited:   true,
+		Remaining: 2,
+		RetryIn:   1500,
+		ResetIn:   3000,
+		Balance:   2,
+		Reason:    InsufficientTokens,
+	}, millis)
+
+	buf, err := json.Marshal(millis)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"limited":true,"remaining":2,"retry_in_ms":1500,"reset_in_ms":3000,"consumed":0,"balance":2,"reason":"InsufficientTokens"}`, string(buf))
+
+	var decoded ResultMillis
+	err = json.Unmarshal(buf, &decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, millis, decoded)
+
+	err = json.Unmarshal([]byte(`{"reason":"foo"}`), &decoded)
+	assert.Error(t, err)
+
+	// sub-millisecond values
+	millis = Result{
+		RetryIn: 1500*time.Millisecond + time.Nanosecond,
+		ResetIn: 1500*time.Millisecond + 499*time.Microsecond,
+	}.ToMillis()
+	assert.Equal(t, int64(1501), millis.RetryIn)
+	assert.Equal(t, int64(1500), millis.ResetIn)
+
+	millis = Result{
+		RetryIn: 999 * time.Microsecond,
+		ResetIn: 1500*time.Millisecond + 500*time.Microsecond,
+	}.ToMillis()
+	assert.Equal(t, int64(1), millis.RetryIn)
+	assert.Equal(t, int64(1501), millis.ResetIn)
+
+	assert.Equal(t, ResultMillis{}, Result{}.ToMillis())
+}
@@ -0,0 +1,30 @@
+package gcra
+
+import "time"
+
+// ObservedRate returns an estimate of the requests per second consumed from
+// the bucket. The estimator assumes that the tokens still outstanding at the
+// TAT have been consumed evenly over the trailing window. A client that keeps
+// the bucket drained is therefore estimated at the sustained rate reported by
+// PerSecond, while an idle client yields zero. Bursts and consumption that
+// has already been regenerated are not distinguished, so the estimate is only
+// meaningful over periods longer than the window. Invalid or unlimited
+// options yield zero.
+func ObservedRate(now time.Time, bucket Bucket, opts Options) float64 {
+	// check options
+	if opts.Burst == Unlimited || opts.check() != nil {
+		return 0
+	}
+
+	// get outstanding duration
+	age := Age(now, bucket, opts)
+	window := opts.Window()
+	if age > window {
+		age = window
+	}
+
+	// compute outstanding tokens
+	outstanding := float64(age) / float64(opts.EmissionInterval())
+
+	return outstanding / window.Seconds()
+}
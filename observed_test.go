@@ -0,0 +1,37 @@
+package gcra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObservedRate(t *testing.T) {
+	opts := Options{
+		Burst:  10,
+		Rate:   5,
+		Period: time.Second,
+	}
+
+	assert.Equal(t, 0.0, ObservedRate(now, Bucket{}, opts))
+	assert.InDelta(t, 5.0, ObservedRate(now, MustGenerate(now, 0, opts), opts), 0.01)
+	assert.InDelta(t, 2.5, ObservedRate(now, MustGenerate(now, 5, opts), opts), 0.01)
+	assert.InDelta(t, 0.5, ObservedRate(now, MustGenerate(now, 9, opts), opts), 0.01)
+	assert.Equal(t, 0.0, ObservedRate(now, MustGenerate(now, 10, opts), opts))
+
+	// a client pinning the limit consumes the sustained rate
+	bucket := MustGenerate(now, 0, opts)
+	for i := 1; i <= 50; i++ {
+		at := now.Add(time.Duration(i) * opts.EmissionInterval())
+		var result Result
+		bucket, result = MustCompute(at, bucket, 1, opts)
+		assert.False(t, result.Limited)
+		assert.InDelta(t, opts.PerSecond(), ObservedRate(at, bucket, opts), 0.01)
+	}
+
+	// future dated buckets are capped
+	assert.InDelta(t, 5.0, ObservedRate(now, Bucket(now.Add(time.Hour)), opts), 0.01)
+
+	assert.Equal(t, 0.0, ObservedRate(now, bucket, Options{}))
+}
@@ -0,0 +1,105 @@
+package gcra
+
+import (
+	"math"
+	"time"
+)
+
+// Option configures options created with New.
+type Option func(*Options)
+
+// WithBurst sets the burst.
+func WithBurst(burst int64) Option {
+	return func(o *Options) {
+		o.Burst = burst
+	}
+}
+
+// WithRate sets the rate.
+func WithRate(rate int64) Option {
+	return func(o *Options) {
+		o.Rate = rate
+	}
+}
+
+// WithPeriod sets the period.
+func WithPeriod(period time.Duration) Option {
+	return func(o *Options) {
+		o.Period = period
+	}
+}
+
+// WithPerSecond sets the rate and period to regenerate tokens at the specified
+// rate per second as described by OptionsFromRate.
+func WithPerSecond(perSecond float64) Option {
+	return func(o *Options) {
+		o.Rate = 1
+		o.Period = periodFromRate(perSecond)
+	}
+}
+
+// New will create and validate options using the provided option functions.
+func New(opts ...Option) (Options, error) {
+	// apply options
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	// check options
+	err := o.check()
+	if err != nil {
+		return Options{}, err
+	}
+
+	return o, nil
+}
+
+// PerWindow returns options that allow count requests per window. The full
+// count is available as a burst and the bucket refills completely over the
+// window, which behaves similar to a token bucket. For example, 60 requests
+// per minute permit a burst of 60 requests and then regenerate one request
+// per second.
+func PerWindow(count int64, window time.Duration) Options {
+	return Options{
+		Burst:  count,
+		Rate:   count,
+		Period: window,
+	}
+}
+
+// OptionsFromRate will create options with the specified burst that regenerate
+// tokens at the specified rate per second. The rate is converted to a period
+// per token, which makes the emission interval exact up to rounding to the
+// nearest nanosecond. The relative error of the sustained rate is therefore
+// bounded by 0.5ns divided by the emission interval.
+func OptionsFromRate(burst int64, perSecond float64) (Options, error) {
+	// compute period
+	period := periodFromRate(perSecond)
+
+	// check arguments
+	if burst <= 0 || period <= 0 {
+		return Options{}, ErrInvalidParameter
+	}
+
+	return Options{
+		Burst:  burst,
+		Rate:   1,
+		Period: period,
+	}, nil
+}
+
+func periodFromRate(perSecond float64) time.Duration {
+	// check rate
+	if !(perSecond > 0) || math.IsInf(perSecond, 0) {
+		return 0
+	}
+
+	// compute period
+	period := math.Round(float64(time.Second) / perSecond)
+	if period < 1 || period >= math.MaxInt64 {
+		return 0
+	}
+
+	return time.Duration(period)
+}
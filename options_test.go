@@ -0,0 +1,109 @@
+package gcra
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptionsFromRate(t *testing.T) {
+	for _, perSecond := range []float64{2.5, 0.1, 1000, 3, 7.77, 1e6} {
+		opts, err := OptionsFromRate(10, perSecond)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(10), opts.Burst)
+
+		sustained := float64(time.Second) / float64(opts.EmissionInterval())
+		assert.InDelta(t, perSecond, sustained, perSecond*1e-6)
+	}
+
+	opts, err := OptionsFromRate(5, 2.5)
+	assert.NoError(t, err)
+	assert.Equal(t, Options{Burst: 5, Rate: 1, Period: 400 * time.Millisecond}, opts)
+
+	opts, err = OptionsFromRate(5, 0.1)
+	assert.NoError(t, err)
+	assert.Equal(t, Options{Burst: 5, Rate: 1, Period: 10 * time.Second}, opts)
+
+	opts, err = OptionsFromRate(5, 1000)
+	assert.NoError(t, err)
+	assert.Equal(t, Options{Burst: 5, Rate: 1, Period: time.Millisecond}, opts)
+
+	for _, perSecond := range []float64{0, -1, math.NaN(), math.Inf(1), 1e10, 1e-10} {
+		_, err = OptionsFromRate(5, perSecond)
+		assert.Equal(t, ErrInvalidParameter, err)
+	}
+
+	_, err = OptionsFromRate(0, 1)
+	assert.Equal(t, ErrInvalidParameter, err)
+}
+
+func TestNew(t *testing.T) {
+	opts, err := New(WithBurst(10), WithRate(5), WithPeriod(time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, Options{Burst: 10, Rate: 5, Period: time.Second}, opts)
+
+	opts, err = New(WithPeriod(time.Minute), WithBurst(1), WithRate(1))
+	assert.NoError(t, err)
+	assert.Equal(t, Options{Burst: 1, Rate: 1, Period: time.Minute}, opts)
+
+	opts, err = New(WithBurst(5), WithPerSecond(2.5))
+	assert.NoError(t, err)
+	assert.Equal(t, Options{Burst: 5, Rate: 1, Period: 400 * time.Millisecond}, opts)
+
+	expected, err := OptionsFromRate(5, 2.5)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, opts)
+
+	opts, err = New(WithBurst(Unlimited), WithRate(1), WithPeriod(time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, Options{Burst: Unlimited, Rate: 1, Period: time.Second}, opts)
+}
+
+func TestNewErrors(t *testing.T) {
+	_, err := New()
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, err = New(WithBurst(10), WithRate(5))
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, err = New(WithBurst(0), WithRate(5), WithPeriod(time.Second))
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, err = New(WithBurst(10), WithPerSecond(0))
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, err = New(WithBurst(10), WithPerSecond(math.NaN()))
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, err = New(WithBurst(10), WithRate(3), WithPeriod(1))
+	assert.Equal(t, ErrZeroInterval, err)
+}
+
+func TestPerWindow(t *testing.T) {
+	opts := PerWindow(60, time.Minute)
+	assert.Equal(t, Options{Burst: 60, Rate: 60, Period: time.Minute}, opts)
+	assert.Equal(t, time.Minute, opts.Window())
+
+	var bucket Bucket
+	var result Result
+	for i := 0; i < 60; i++ {
+		bucket, result = MustCompute(now, bucket, 1, opts)
+		assert.False(t, result.Limited)
+	}
+	assert.Equal(t, int64(0), result.Remaining)
+
+	_, result = MustCompute(now, bucket, 1, opts)
+	assert.True(t, result.Limited)
+	assert.Equal(t, time.Second, result.RetryIn)
+
+	bucket, result = MustCompute(now.Add(time.Second), bucket, 1, opts)
+	assert.False(t, result.Limited)
+
+	_, result = MustCompute(now.Add(time.Second), bucket, 1, opts)
+	assert.True(t, result.Limited)
+
+	_, result = MustCompute(now.Add(time.Minute+time.Second), bucket, 0, opts)
+	assert.Equal(t, int64(60), result.Remaining)
+}
@@ -0,0 +1,76 @@
+package gcra
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidSpec is returned if an options spec cannot be parsed.
+var ErrInvalidSpec = errors.New("invalid spec")
+
+// ParseOptions will parse the provided spec into options. The spec must have
+// the form "<burst>/<period>" (e.g. "100/1m") or "<burst>;<rate>/<period>"
+// (e.g. "100;10/1s"). If the rate is omitted, it defaults to the burst. The
+// period may be any Go duration or a bare unit like "s", "m" or "h".
+func ParseOptions(spec string) (Options, error) {
+	// split limit and period
+	limit, period, ok := cut(spec, "/")
+	if !ok {
+		return Options{}, fmt.Errorf("%w: %q: missing period", ErrInvalidSpec, spec)
+	}
+
+	// split burst and rate
+	burstStr, rateStr, hasRate := cut(limit, ";")
+
+	// parse burst
+	burst, err := strconv.ParseInt(strings.TrimSpace(burstStr), 10, 64)
+	if err != nil || burst <= 0 {
+		return Options{}, fmt.Errorf("%w: %q: invalid burst", ErrInvalidSpec, spec)
+	}
+
+	// parse rate
+	rate := burst
+	if hasRate {
+		rate, err = strconv.ParseInt(strings.TrimSpace(rateStr), 10, 64)
+		if err != nil || rate <= 0 {
+			return Options{}, fmt.Errorf("%w: %q: invalid rate", ErrInvalidSpec, spec)
+		}
+	}
+
+	// parse period, allowing bare units
+	period = strings.TrimSpace(period)
+	if r, _ := utf8.DecodeRuneInString(period); unicode.IsLetter(r) {
+		period = "1" + period
+	}
+	duration, err := time.ParseDuration(period)
+	if err != nil || duration <= 0 {
+		return Options{}, fmt.Errorf("%w: %q: invalid period", ErrInvalidSpec, spec)
+	}
+
+	return Options{
+		Burst:  burst,
+		Rate:   rate,
+		Period: duration,
+	}, nil
+}
+
+// MustParseOptions will call ParseOptions and panic on errors.
+func MustParseOptions(spec string) Options {
+	opts, err := ParseOptions(spec)
+	if err != nil {
+		panic(err)
+	}
+	return opts
+}
+
+func cut(s, sep string) (string, string, bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
@@ -0,0 +1,65 @@
+package gcra
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOptions(t *testing.T) {
+	table := []struct {
+		spec string
+		opts Options
+	}{
+		{"100/1m", Options{Burst: 100, Rate: 100, Period: time.Minute}},
+		{"10/s", Options{Burst: 10, Rate: 10, Period: time.Second}},
+		{"10/m", Options{Burst: 10, Rate: 10, Period: time.Minute}},
+		{"10/h", Options{Burst: 10, Rate: 10, Period: time.Hour}},
+		{"5/ms", Options{Burst: 5, Rate: 5, Period: time.Millisecond}},
+		{"5/µs", Options{Burst: 5, Rate: 5, Period: time.Microsecond}},
+		{"10/.5s", Options{Burst: 10, Rate: 10, Period: 500 * time.Millisecond}},
+		{"10/0.5s", Options{Burst: 10, Rate: 10, Period: 500 * time.Millisecond}},
+		{"10/1h30m", Options{Burst: 10, Rate: 10, Period: 90 * time.Minute}},
+		{"100;10/1s", Options{Burst: 100, Rate: 10, Period: time.Second}},
+		{"100;10/s", Options{Burst: 100, Rate: 10, Period: time.Second}},
+		{" 100 ; 10 / 2s ", Options{Burst: 100, Rate: 10, Period: 2 * time.Second}},
+	}
+
+	for _, item := range table {
+		opts, err := ParseOptions(item.spec)
+		assert.NoError(t, err, item.spec)
+		assert.Equal(t, item.opts, opts, item.spec)
+	}
+
+	assert.Equal(t, Options{Burst: 10, Rate: 10, Period: time.Second}, MustParseOptions("10/s"))
+}
+
+func TestParseOptionsErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"100",
+		"/1s",
+		"abc/1s",
+		"0/1s",
+		"-1/1s",
+		"10;/1s",
+		"10;0/1s",
+		"10;x/1s",
+		"10/",
+		"10/0s",
+		"10/-1s",
+		"10/x",
+		"10/1s/1s",
+		"10/.s",
+	} {
+		_, err := ParseOptions(spec)
+		assert.Error(t, err, spec)
+		assert.True(t, errors.Is(err, ErrInvalidSpec), spec)
+	}
+
+	assert.Panics(t, func() {
+		MustParseOptions("foo")
+	})
+}
@@ -0,0 +1,25 @@
+package gcra
+
+import "math/bits"
+
+// Percent returns the remaining tokens as a percentage of the burst in the
+// range [0, 100]. Unlimited options yield 100 and invalid options yield zero.
+func (r Result) Percent(opts Options) int {
+	// handle unlimited
+	if opts.Burst == Unlimited {
+		return 100
+	}
+
+	// check burst
+	burst := opts.burst(false)
+	if burst <= 0 {
+		return 0
+	}
+
+	// compute percentage (the product may exceed 64 bits for large bursts)
+	remaining := clamp(r.Remaining, 0, burst)
+	hi, lo := bits.Mul64(uint64(remaining), 100)
+	percent, _ := bits.Div64(hi, lo, uint64(burst))
+
+	return int(percent)
+}
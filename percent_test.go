@@ -0,0 +1,42 @@
+package gcra
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultPercent(t *testing.T) {
+	opts := Options{
+		Burst:  8,
+		Rate:   1,
+		Period: time.Second,
+	}
+
+	for count, percent := range map[int64]int{
+		0: 0,
+		1: 12,
+		2: 25,
+		4: 50,
+		7: 87,
+		8: 100,
+	} {
+		_, result := MustCompute(now, MustGenerate(now, count, opts), 0, opts)
+		assert.Equal(t, percent, result.Percent(opts), "count=%d", count)
+	}
+
+	assert.Equal(t, 100, Result{Remaining: 20}.Percent(opts))
+	assert.Equal(t, 0, Result{Remaining: -2}.Percent(opts))
+	assert.Equal(t, 0, Result{Remaining: 5}.Percent(Options{}))
+	assert.Equal(t, 100, Result{}.Percent(Options{Burst: Unlimited, Rate: 1, Period: 1}))
+
+	// large bursts do not overflow
+	large := Options{Burst: math.MaxInt64 / 10, Rate: 1, Period: time.Second}
+	assert.Equal(t, 20, Result{Remaining: large.Burst / 5}.Percent(large))
+	assert.Equal(t, 99, Result{Remaining: large.Burst - 1}.Percent(large))
+	assert.Equal(t, 100, Result{Remaining: large.Burst}.Percent(large))
+	large.Burst = math.MaxInt64
+	assert.Equal(t, 50, Result{Remaining: math.MaxInt64/2 + 1}.Percent(large))
+}
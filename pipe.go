@@ -0,0 +1,44 @@
+package gcra
+
+import "time"
+
+// Request is a request processed by PoolLimiter.Pipe.
+type Request struct {
+	Key  string
+	Cost int64
+
+	// Time specifies the time of the request. If zero, the current time is
+	// used.
+	Time time.Time
+}
+
+// Decision is the decision for a request processed by PoolLimiter.Pipe.
+type Decision struct {
+	Request Request
+	Result  Result
+	Error   error
+}
+
+// Pipe will read requests from the input channel, limit them using Limit and
+// send the decisions in order to the output channel. It blocks until the input
+// channel is closed and then closes the output channel. The function does not
+// start any goroutines, so it is usually run in its own goroutine as a stage
+// of a pipeline.
+func (l *PoolLimiter) Pipe(in <-chan Request, out chan<- Decision) {
+	// ensure output is closed
+	defer close(out)
+
+	// process requests
+	for req := range in {
+		now := req.Time
+		if now.IsZero() {
+			now = time.Now()
+		}
+		result, err := l.Limit(now, req.Key, req.Cost)
+		out <- Decision{
+			Request: req,
+			Result:  result,
+			Error:   err,
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package gcra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoolLimiterPipe(t *testing.T) {
+	l := NewPoolLimiter(Options{
+		Burst:  2,
+		Rate:   1,
+		Period: time.Second,
+	}, Options{
+		Burst:  10,
+		Rate:   1,
+		Period: time.Second,
+	})
+
+	in := make(chan Request)
+	out := make(chan Decision)
+	done := make(chan struct{})
+	go func() {
+		l.Pipe(in, out)
+		close(done)
+	}()
+
+	requests := []Request{
+		{Key: "a", Cost: 1, Time: now},
+		{Key: "a", Cost: 1, Time: now},
+		{Key: "a", Cost: 1, Time: now},
+		{Key: "b", Cost: 2, Time: now},
+		{Key: "a", Cost: 1, Time: now.Add(time.Second)},
+		{Key: "b", Cost: 3, Time: now},
+	}
+	go func() {
+		for _, req := range requests {
+			in <- req
+		}
+		close(in)
+	}()
+
+	var decisions []Decision
+	for decision := range out {
+		decisions = append(decisions, decision)
+	}
+	<-done
+
+	assert.Len(t, decisions, len(requests))
+	for i, decision := range decisions {
+		assert.Equal(t, requests[i], decision.Request)
+	}
+
+	var limited []bool
+	for _, decision := range decisions[:5] {
+		assert.NoError(t, decision.Error)
+		limited = append(limited, decision.Result.Limited)
+	}
+	assert.Equal(t, []bool{false, false, true, false, false}, limited)
+	assert.Equal(t, ErrCostHigherThanBurst, decisions[5].Error)
+}
+
+func TestPoolLimiterPipeClose(t *testing.T) {
+	l := NewPoolLimiter(Options{
+		Burst:  1,
+		Rate:   1,
+		Period: time.Second,
+	}, Options{
+		Burst:  1,
+		Rate:   1,
+		Period: time.Second,
+	})
+
+	in := make(chan Request)
+	out := make(chan Decision, 1)
+	close(in)
+
+	l.Pipe(in, out)
+
+	_, ok := <-out
+	assert.False(t, ok)
+
+	// current time
+	in = make(chan Request, 1)
+	out = make(chan Decision, 1)
+	in <- Request{Key: "a", Cost: 1}
+	close(in)
+
+	l.Pipe(in, out)
+
+	decision := <-out
+	assert.NoError(t, decision.Error)
+	assert.False(t, decision.Result.Limited)
+	_, ok = <-out
+	assert.False(t, ok)
+}
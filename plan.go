@@ -0,0 +1,74 @@
+package gcra
+
+import "time"
+
+// BatchPlan describes the admission of a batch of items as returned by Plan.
+type BatchPlan struct {
+	// AdmittedNow is the amount of items that are admitted immediately.
+	AdmittedNow int64
+
+	// TotalWait is the duration after which all items have been admitted if
+	// the remaining items are queued. Due to the rounding of tokens, the
+	// items may be admitted up to half an emission interval earlier.
+	TotalWait time.Duration
+
+	// FinalBucket is the bucket after all items have been admitted.
+	FinalBucket Bucket
+}
+
+// Plan will compute the admission of a batch of items with the specified cost
+// per item without looping. The base cost is charged for every item and items
+// are never rejected early. The bucket is not modified. Plan returns ErrCostHigherThanBurst if a single item can
+// never be admitted.
+func Plan(now time.Time, bucket Bucket, items, costPer int64, opts Options) (BatchPlan, error) {
+	// apply items at the current time
+	_, result, err := Apply(now, bucket, items, costPer, opts)
+	if err != nil {
+		return BatchPlan{}, err
+	}
+
+	// handle unlimited and queries
+	if opts.Burst == Unlimited || costPer == 0 {
+		return BatchPlan{
+			AdmittedNow: items,
+			FinalBucket: bucket,
+		}, nil
+	}
+
+	// determine admitted items
+	charged := costPer + opts.BaseCost
+	admitted := result.Consumed / charged
+
+	// get resolution and emission interval
+	res := opts.resolution()
+	interval := roundDiv(int64(opts.EmissionInterval()), int64(res))
+
+	// reset TAT if smaller than now
+	bucket = opts.initial(now, bucket)
+	nowUnits := toUnits(now, res)
+	tat := toUnits(time.Time(bucket), res)
+	if time.Time(bucket).IsZero() || nowUnits > tat {
+		tat = nowUnits
+	}
+
+	// compute final TAT including all items and their base costs
+	finalTAT := mulAdd(interval, mulAdd(items, charged, 0), tat)
+	if saturated(finalTAT) {
+		return BatchPlan{}, ErrOverflow
+	}
+
+	// compute wait until the last item fits into the bucket
+	var wait time.Duration
+	if admitted < items {
+		allowAt := mulAdd(-interval, opts.burst(false), finalTAT)
+		if allowAt > nowUnits {
+			wait = time.Duration(allowAt-nowUnits) * res
+		}
+	}
+
+	return BatchPlan{
+		AdmittedNow: admitted,
+		TotalWait:   wait,
+		FinalBucket: Bucket(fromUnits(finalTAT, res)),
+	}, nil
+}
@@ -0,0 +1,140 @@
+package gcra
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlan(t *testing.T) {
+	opts := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+	}
+
+	// within burst
+	plan, err := Plan(now, Bucket{}, 4, 2, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, BatchPlan{
+		AdmittedNow: 4,
+		TotalWait:   0,
+		FinalBucket: Bucket(time.Unix(0, now.Add(800*time.Millisecond).UnixNano())),
+	}, plan)
+
+	bucket, result := MustCompute(now, Bucket{}, 8, opts)
+	assert.False(t, result.Limited)
+	assert.Equal(t, bucket, plan.FinalBucket)
+
+	// requiring wait
+	plan, err = Plan(now, Bucket{}, 100, 2, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, BatchPlan{
+		AdmittedNow: 5,
+		TotalWait:   19 * time.Second,
+		FinalBucket: Bucket(time.Unix(0, now.Add(20*time.Second).UnixNano())),
+	}, plan)
+
+	// verify by looping
+	var admitted int64
+	bucket = Bucket{}
+	at := now
+	for admitted < 100 {
+		var result Result
+		bucket, result = MustCompute(at, bucket, 2, opts)
+		if result.Limited {
+			at = at.Add(result.RetryIn)
+		} else {
+			admitted++
+		}
+	}
+	assert.Equal(t, now.Add(19*time.Second), at)
+	assert.Equal(t, plan.FinalBucket, bucket)
+
+	// partially drained bucket
+	plan, err = Plan(now, MustGenerate(now, 3, opts), 3, 2, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), plan.AdmittedNow)
+	assert.Equal(t, 300*time.Millisecond, plan.TotalWait)
+
+	// impossibly large
+	_, err = Plan(now, Bucket{}, 1, 11, opts)
+	assert.Equal(t, ErrCostHigherThanBurst, err)
+
+	_, err = Plan(now, Bucket{}, -1, 1, opts)
+	assert.Equal(t, ErrInvalidParameter, err)
+}
+
+func TestPlanBaseCost(t *testing.T) {
+	opts := Options{
+		Burst:    10,
+		Rate:     10,
+		Period:   time.Second,
+		BaseCost: 2,
+	}
+
+	// within burst
+	plan, err := Plan(now, Bucket{}, 3, 1, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, BatchPlan{
+		AdmittedNow: 3,
+		TotalWait:   0,
+		FinalBucket: Bucket(time.Unix(0, now.Add(900*time.Millisecond).UnixNano())),
+	}, plan)
+
+	// requiring wait
+	plan, err = Plan(now, Bucket{}, 5, 1, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, BatchPlan{
+		AdmittedNow: 3,
+		TotalWait:   500 * time.Millisecond,
+		FinalBucket: Bucket(time.Unix(0, now.Add(1500*time.Millisecond).UnixNano())),
+	}, plan)
+
+	// verify by looping
+	var admitted int64
+	bucket := Bucket{}
+	at := now
+	for admitted < 5 {
+		var result Result
+		bucket, result = MustCompute(at, bucket, 1, opts)
+		if result.Limited {
+			at = at.Add(result.RetryIn)
+		} else {
+			admitted++
+		}
+	}
+	assert.Equal(t, now.Add(plan.TotalWait), at)
+	assert.Equal(t, plan.FinalBucket, bucket)
+}
+
+func TestPlanUnlimited(t *testing.T) {
+	plan, err := Plan(now, Bucket{}, math.MaxInt32, 5, Options{
+		Burst:  Unlimited,
+		Rate:   1,
+		Period: time.Second,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, BatchPlan{
+		AdmittedNow: math.MaxInt32,
+	}, plan)
+}
+
+func TestPlanWarmUp(t *testing.T) {
+	opts := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+		WarmUp: 500 * time.Millisecond,
+	}
+
+	plan, err := Plan(now, Bucket{}, 20, 1, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, BatchPlan{
+		AdmittedNow: 5,
+		TotalWait:   1500 * time.Millisecond,
+		FinalBucket: Bucket(time.Unix(0, now.Add(2500*time.Millisecond).UnixNano())),
+	}, plan)
+}
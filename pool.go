@@ -0,0 +1,207 @@
+package gcra
+
+import (
+	"container/list"
+	"math"
+	"sync"
+	"time"
+)
+
+// PoolLimiter limits requests per key while all keys also draw from a shared
+// pool. The cost charged against the pool is scaled by the weight of the key.
+// The memory used by the key buckets may be bounded using SetMaxKeys. It is
+// safe for concurrent use.
+type PoolLimiter struct {
+	keyOpts    Options
+	sharedOpts Options
+	mutex      sync.Mutex
+	maxKeys    int
+	shared     Bucket
+	buckets    map[string]*list.Element
+	recent     *list.List
+	weights    map[string]float64
+}
+
+type poolEntry struct {
+	key    string
+	bucket Bucket
+}
+
+// NewPoolLimiter creates and returns a new pool limiter using the specified
+// options for the per key buckets and the shared bucket.
+func NewPoolLimiter(keyOpts, sharedOpts Options) *PoolLimiter {
+	return &PoolLimiter{
+		keyOpts:    keyOpts,
+		sharedOpts: sharedOpts,
+		buckets:    map[string]*list.Element{},
+		recent:     list.New(),
+		weights:    map[string]float64{},
+	}
+}
+
+// SetWeight sets the weight of the specified key. The cost of requests charged
+// against the shared bucket is multiplied by the weight and rounded to the
+// nearest token. Keys without a weight use a weight of one. The weight must be
+// positive and finite, otherwise ErrInvalidParameter is returned.
+func (l *PoolLimiter) SetWeight(key string, weight float64) error {
+	// check weight
+	if !(weight > 0) || math.IsInf(weight, 1) {
+		return ErrInvalidParameter
+	}
+
+	// acquire mutex
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// set weight, the default weight is not stored
+	if weight == 1 {
+		delete(l.weights, key)
+	} else {
+		l.weights[key] = weight
+	}
+
+	return nil
+}
+
+// SetMaxKeys sets the maximum amount of keys with buckets. If a new key exceeds
+// the maximum, the bucket of the least recently used key is dropped and that
+// key behaves like a new key on its next request. Lowering the maximum drops
+// the buckets of excess keys immediately. Zero removes the maximum, negative
+// values return ErrInvalidParameter.
+func (l *PoolLimiter) SetMaxKeys(max int) error {
+	// check max
+	if max < 0 {
+		return ErrInvalidParameter
+	}
+
+	// acquire mutex
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// set max and evict excess keys
+	l.maxKeys = max
+	l.evict()
+
+	return nil
+}
+
+// Delete will drop the bucket of the specified key, e.g. once a user has been
+// deleted. The key behaves like a new key on its next request. Deleting a
+// missing key is a no-op. The weight of the key is kept.
+func (l *PoolLimiter) Delete(key string) {
+	// acquire mutex
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// drop bucket
+	if elem, ok := l.buckets[key]; ok {
+		l.remove(elem)
+	}
+}
+
+// Prune will drop the buckets of keys that are full at the provided time.
+// Dropped keys behave like new keys on their next request. Prune should be
+// called periodically to release the memory of idle keys.
+func (l *PoolLimiter) Prune(now time.Time) {
+	// acquire mutex
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// drop full buckets
+	for _, elem := range l.buckets {
+		if !time.Time(elem.Value.(*poolEntry).bucket).After(now) {
+			l.remove(elem)
+		}
+	}
+}
+
+// Limit will charge the cost against the bucket of the key and the weighted
+// cost against the shared bucket. The request is only admitted and both
+// buckets are only updated if neither bucket limits the request. The result
+// of the limiting bucket is returned or the result with fewer remaining tokens
+// if the request has been admitted.
+func (l *PoolLimiter) Limit(now time.Time, key string, cost int64) (Result, error) {
+	// acquire mutex
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// get weighted cost
+	weight, ok := l.weights[key]
+	if !ok {
+		weight = 1
+	}
+	sharedCost := int64(math.Round(float64(cost) * weight))
+
+	// compute key bucket
+	keyBucket, keyResult, err := Compute(now, l.get(key), cost, l.keyOpts)
+	if err != nil {
+		return Result{}, err
+	}
+
+	// compute shared bucket
+	sharedBucket, sharedResult, err := Compute(now, l.shared, sharedCost, l.sharedOpts)
+	if err != nil {
+		return Result{}, err
+	}
+
+	// handle limited, new buckets are still initialized so that the warm up
+	// is only applied once
+	if keyResult.Limited || sharedResult.Limited {
+		if bucket := l.keyOpts.initial(now, l.get(key)); !time.Time(bucket).IsZero() {
+			l.set(key, bucket)
+		}
+		l.shared = l.sharedOpts.initial(now, l.shared)
+		if keyResult.Limited && (!sharedResult.Limited || keyResult.RetryIn >= sharedResult.RetryIn) {
+			keyResult.Consumed = 0
+			return keyResult, nil
+		}
+		sharedResult.Consumed = 0
+		return sharedResult, nil
+	}
+
+	// commit buckets
+	l.set(key, keyBucket)
+	l.shared = sharedBucket
+
+	// return stricter result
+	if sharedResult.Remaining < keyResult.Remaining {
+		return sharedResult, nil
+	}
+
+	return keyResult, nil
+}
+
+func (l *PoolLimiter) get(key string) Bucket {
+	// get entry and mark as recently used
+	elem, ok := l.buckets[key]
+	if !ok {
+		return Bucket{}
+	}
+	l.recent.MoveToFront(elem)
+
+	return elem.Value.(*poolEntry).bucket
+}
+
+func (l *PoolLimiter) set(key string, bucket Bucket) {
+	// update existing entry
+	if elem, ok := l.buckets[key]; ok {
+		elem.Value.(*poolEntry).bucket = bucket
+		l.recent.MoveToFront(elem)
+		return
+	}
+
+	// add entry and evict excess keys
+	l.buckets[key] = l.recent.PushFront(&poolEntry{key: key, bucket: bucket})
+	l.evict()
+}
+
+func (l *PoolLimiter) remove(elem *list.Element) {
+	l.recent.Remove(elem)
+	delete(l.buckets, elem.Value.(*poolEntry).key)
+}
+
+func (l *PoolLimiter) evict() {
+	for l.maxKeys > 0 && len(l.buckets) > l.maxKeys {
+		l.remove(l.recent.Back())
+	}
+}
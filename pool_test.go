@@ -0,0 +1,258 @@
+package gcra
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoolLimiterSharedRejects(t *testing.T) {
+	l := NewPoolLimiter(Options{
+		Burst:  5,
+		Rate:   1,
+		Period: time.Second,
+	}, Options{
+		Burst:  8,
+		Rate:   1,
+		Period: time.Second,
+	})
+
+	result, err := l.Limit(now, "a", 5)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+	assert.Equal(t, int64(0), result.Remaining)
+
+	result, err = l.Limit(now, "b", 4)
+	assert.NoError(t, err)
+	assert.True(t, result.Limited)
+	assert.Equal(t, int64(3), result.Remaining)
+	assert.Equal(t, time.Second, result.RetryIn)
+
+	// the key bucket has not been charged
+	result, err = l.Limit(now, "b", 3)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+	assert.Equal(t, int64(0), result.Remaining)
+}
+
+func TestPoolLimiterKeyRejects(t *testing.T) {
+	l := NewPoolLimiter(Options{
+		Burst:  2,
+		Rate:   1,
+		Period: time.Second,
+	}, Options{
+		Burst:  10,
+		Rate:   1,
+		Period: time.Second,
+	})
+
+	result, err := l.Limit(now, "a", 2)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+
+	result, err = l.Limit(now, "a", 1)
+	assert.NoError(t, err)
+	assert.True(t, result.Limited)
+	assert.Equal(t, time.Second, result.RetryIn)
+
+	// the shared bucket has not been charged
+	for i := 0; i < 4; i++ {
+		result, err = l.Limit(now, string(rune('b'+i)), 2)
+		assert.NoError(t, err)
+		assert.False(t, result.Limited)
+	}
+	assert.Equal(t, int64(0), result.Remaining)
+}
+
+func TestPoolLimiterWeight(t *testing.T) {
+	l := NewPoolLimiter(Options{
+		Burst:  10,
+		Rate:   1,
+		Period: time.Second,
+	}, Options{
+		Burst:  10,
+		Rate:   1,
+		Period: time.Second,
+	})
+	assert.NoError(t, l.SetWeight("low", 2))
+	assert.NoError(t, l.SetWeight("high", 0.5))
+
+	result, err := l.Limit(now, "low", 3)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+	assert.Equal(t, int64(4), result.Remaining)
+
+	result, err = l.Limit(now, "high", 8)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+	assert.Equal(t, int64(0), result.Remaining)
+
+	result, err = l.Limit(now, "low", 1)
+	assert.NoError(t, err)
+	assert.True(t, result.Limited)
+}
+
+func TestPoolLimiterSetWeightInvalid(t *testing.T) {
+	l := NewPoolLimiter(Options{Burst: 10, Rate: 1, Period: time.Second}, Options{Burst: 10, Rate: 1, Period: time.Second})
+
+	for _, weight := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		assert.Equal(t, ErrInvalidParameter, l.SetWeight("a", weight), weight)
+	}
+	assert.Empty(t, l.weights)
+
+	// the default weight is not stored
+	assert.NoError(t, l.SetWeight("a", 2))
+	assert.NoError(t, l.SetWeight("a", 1))
+	assert.Empty(t, l.weights)
+}
+
+func TestPoolLimiterPrune(t *testing.T) {
+	l := NewPoolLimiter(Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+	}, Options{
+		Burst:  20,
+		Rate:   10,
+		Period: time.Second,
+	})
+
+	result, err := l.Limit(now, "a", 5)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+	result, err = l.Limit(now.Add(400*time.Millisecond), "b", 5)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+
+	// keys are kept until their buckets are full
+	l.Prune(now.Add(400 * time.Millisecond))
+	assert.Len(t, l.buckets, 2)
+	l.Prune(now.Add(500 * time.Millisecond))
+	assert.Len(t, l.buckets, 1)
+	l.Prune(now.Add(900 * time.Millisecond))
+	assert.Empty(t, l.buckets)
+
+	// pruned keys behave like new keys
+	result, err = l.Limit(now.Add(time.Second), "a", 10)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+}
+
+func TestPoolLimiterDelete(t *testing.T) {
+	l := NewPoolLimiter(Options{
+		Burst:  5,
+		Rate:   1,
+		Period: time.Second,
+	}, Options{
+		Burst:  20,
+		Rate:   1,
+		Period: time.Second,
+	})
+
+	result, err := l.Limit(now, "a", 5)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+
+	result, err = l.Limit(now, "a", 1)
+	assert.NoError(t, err)
+	assert.True(t, result.Limited)
+
+	// deleted keys behave like new keys
+	l.Delete("a")
+	assert.Empty(t, l.buckets)
+	result, err = l.Limit(now, "a", 5)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+
+	// deleting missing keys is a no-op
+	l.Delete("b")
+	assert.Len(t, l.buckets, 1)
+}
+
+func TestPoolLimiterMaxKeys(t *testing.T) {
+	l := NewPoolLimiter(Options{
+		Burst:  5,
+		Rate:   1,
+		Period: time.Second,
+	}, Options{
+		Burst:  100,
+		Rate:   1,
+		Period: time.Second,
+	})
+	assert.Equal(t, ErrInvalidParameter, l.SetMaxKeys(-1))
+	assert.NoError(t, l.SetMaxKeys(3))
+
+	// fill beyond the maximum while keeping "a" recently used
+	for _, key := range []string{"a", "b", "a", "c", "d", "a", "e"} {
+		result, err := l.Limit(now, key, 1)
+		assert.NoError(t, err)
+		assert.False(t, result.Limited)
+	}
+	assert.Len(t, l.buckets, 3)
+	assert.Contains(t, l.buckets, "a")
+	assert.Contains(t, l.buckets, "d")
+	assert.Contains(t, l.buckets, "e")
+
+	// the recently used key survived
+	result, err := l.Limit(now, "a", 2)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+	assert.Equal(t, int64(0), result.Remaining)
+
+	// evicted keys behave like new keys
+	result, err = l.Limit(now, "b", 5)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+	assert.NotContains(t, l.buckets, "d")
+
+	// lowering the maximum evicts immediately
+	assert.NoError(t, l.SetMaxKeys(1))
+	assert.Len(t, l.buckets, 1)
+	assert.Contains(t, l.buckets, "b")
+	assert.Equal(t, 1, l.recent.Len())
+
+	// zero removes the maximum
+	assert.NoError(t, l.SetMaxKeys(0))
+	for _, key := range []string{"f", "g", "h"} {
+		_, err = l.Limit(now, key, 1)
+		assert.NoError(t, err)
+	}
+	assert.Len(t, l.buckets, 4)
+}
+
+func TestPoolLimiterErrors(t *testing.T) {
+	l := NewPoolLimiter(Options{Burst: 2, Rate: 1, Period: time.Second}, Options{Burst: 1, Rate: 1, Period: time.Second})
+
+	_, err := l.Limit(now, "a", 3)
+	assert.Equal(t, ErrCostHigherThanBurst, err)
+
+	_, err = l.Limit(now, "a", 2)
+	assert.Equal(t, ErrCostHigherThanBurst, err)
+}
+
+func TestPoolLimiterWarmUp(t *testing.T) {
+	l := NewPoolLimiter(Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+		WarmUp: time.Second,
+	}, Options{
+		Burst:  100,
+		Rate:   100,
+		Period: time.Second,
+		WarmUp: time.Second,
+	})
+
+	// the warm up is only applied to the first request
+	var admitted int
+	for i := 0; i < 100; i++ {
+		result, err := l.Limit(now.Add(time.Duration(i)*100*time.Millisecond), "a", 1)
+		assert.NoError(t, err)
+		if !result.Limited {
+			admitted++
+		}
+	}
+	assert.Equal(t, 99, admitted)
+}
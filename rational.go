@@ -0,0 +1,126 @@
+package gcra
+
+import "time"
+
+// Rational is a fraction of two integers.
+type Rational struct {
+	Num int64
+	Den int64
+}
+
+// Float returns the value of the rational as a float.
+func (r Rational) Float() float64 {
+	return float64(r.Num) / float64(r.Den)
+}
+
+// RationalOptions define the GCRA options using rationals for the burst and
+// rate, e.g. a rate of {5, 2} per second for exactly 2.5 tokens per second.
+type RationalOptions struct {
+	Burst  Rational
+	Rate   Rational
+	Period time.Duration
+}
+
+// ComputeRational will perform the GCRA like Compute using rational options
+// and cost. The computation is performed exactly using integers scaled by the
+// denominators, so that unlike with Options, the emission interval is never
+// rounded. Only the resulting TAT and ResetIn are rounded to the nearest
+// nanosecond, RetryIn is rounded up and token counts are rounded to the nearest
+// token. The computation fails with ErrOverflow if the scaled values cannot be
+// represented.
+func ComputeRational(now time.Time, bucket Bucket, cost Rational, opts RationalOptions) (Bucket, Result, error) {
+	// check arguments
+	if cost.Num < 0 || cost.Den <= 0 || opts.Burst.Num <= 0 || opts.Burst.Den <= 0 || opts.Rate.Num <= 0 || opts.Rate.Den <= 0 || opts.Period <= 0 {
+		return bucket, Result{}, ErrInvalidParameter
+	}
+
+	// determine token scale and scaled burst and cost
+	tokenScale := lcm(opts.Burst.Den, cost.Den)
+	burst := mulAdd(tokenScale/opts.Burst.Den, opts.Burst.Num, 0)
+	scaledCost := mulAdd(tokenScale/cost.Den, cost.Num, 0)
+
+	// determine time scale such that the emission interval of a scaled token
+	// is an integer in scaled nanoseconds
+	timeScale := mulAdd(opts.Rate.Num, tokenScale, 0)
+	interval := mulAdd(int64(opts.Period), opts.Rate.Den, 0)
+
+	// calculate scaled TAT relative to now, a TAT in the past is reset to now
+	// before scaling so that stale buckets do not overflow
+	var tat int64
+	if !time.Time(bucket).IsZero() && time.Time(bucket).After(now) {
+		tat = mulAdd(mulAdd(now.UnixNano(), -1, time.Time(bucket).UnixNano()), timeScale, 0)
+	}
+
+	// check overflow
+	for _, v := range []int64{burst, scaledCost, timeScale, interval, tat} {
+		if saturated(v) {
+			return bucket, Result{}, ErrOverflow
+		}
+	}
+
+	// check cost
+	if scaledCost > burst {
+		return bucket, Result{}, ErrCostHigherThanBurst
+	}
+
+	// compute GCRA
+	explanation := Explain(tat, 0, burst, 1, interval, scaledCost)
+	if saturated(explanation.Result) || saturated(explanation.RetryIn) {
+		return bucket, Result{}, ErrOverflow
+	}
+
+	// prepare result
+	result := Result{
+		Limited:   explanation.Limited,
+		Remaining: nearestDiv(explanation.Remaining, tokenScale),
+		RetryIn:   time.Duration(ceilDiv(explanation.RetryIn, timeScale)),
+		ResetIn:   time.Duration(nearestDiv(explanation.ResetIn, timeScale)),
+		Balance:   nearestDiv(explanation.Remaining, tokenScale),
+		Reason:    explanation.Reason,
+	}
+
+	// handle limited
+	if result.Limited {
+		return bucket, result, nil
+	}
+
+	// set consumed
+	result.Consumed = nearestDiv(scaledCost, tokenScale)
+
+	// compute TAT
+	newTAT := mulAdd(nearestDiv(explanation.Result, timeScale), 1, now.UnixNano())
+	if saturated(newTAT) {
+		return bucket, Result{}, ErrOverflow
+	}
+
+	return Bucket(time.Unix(0, newTAT)), result, nil
+}
+
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func lcm(a, b int64) int64 {
+	return mulAdd(a/gcd(a, b), b, 0)
+}
+
+// nearestDiv returns a/b rounded to the nearest integer for a >= 0 and b > 0.
+func nearestDiv(a, b int64) int64 {
+	q := a / b
+	if a%b >= b-a%b {
+		q++
+	}
+	return q
+}
+
+// ceilDiv returns a/b rounded up for b > 0.
+func ceilDiv(a, b int64) int64 {
+	q := a / b
+	if a%b > 0 {
+		q++
+	}
+	return q
+}
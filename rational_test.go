@@ -0,0 +1,210 @@
+package gcra
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeRational(t *testing.T) {
+	opts := RationalOptions{
+		Burst:  Rational{5, 2},
+		Rate:   Rational{5, 2},
+		Period: time.Second,
+	}
+
+	// five half tokens are admitted (consumed tokens are rounded)
+	var bucket Bucket
+	var result Result
+	var err error
+	for i := 0; i < 5; i++ {
+		bucket, result, err = ComputeRational(now, bucket, Rational{1, 2}, opts)
+		assert.NoError(t, err)
+		assert.False(t, result.Limited)
+	}
+	assert.Equal(t, Result{
+		Remaining: 0,
+		ResetIn:   time.Second,
+		Consumed:  1,
+		Balance:   0,
+	}, result)
+
+	// a half token regenerates in 200ms
+	newBucket, result, err := ComputeRational(now, bucket, Rational{1, 2}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, bucket, newBucket)
+	assert.Equal(t, Result{
+		Limited:   true,
+		Remaining: 0,
+		RetryIn:   200 * time.Millisecond,
+		ResetIn:   time.Second,
+		Reason:    InsufficientTokens,
+	}, result)
+
+	// a full token regenerates in 400ms
+	_, result, err = ComputeRational(now.Add(250*time.Millisecond), bucket, Rational{1, 1}, opts)
+	assert.NoError(t, err)
+	assert.True(t, result.Limited)
+	assert.Equal(t, 150*time.Millisecond, result.RetryIn)
+	_, result, err = ComputeRational(now.Add(400*time.Millisecond), bucket, Rational{1, 1}, opts)
+	assert.NoError(t, err)
+	assert.False(t, result.Limited)
+	assert.Equal(t, int64(1), result.Consumed)
+}
+
+func TestComputeRationalAgreement(t *testing.T) {
+	opts := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+	}
+	rationalOpts := RationalOptions{
+		Burst:  Rational{20, 2},
+		Rate:   Rational{10, 1},
+		Period: time.Second,
+	}
+
+	var bucket, rationalBucket Bucket
+	for i, step := range []struct {
+		offset time.Duration
+		cost   int64
+	}{
+		{0, 4},
+		{0, 0},
+		{0, 6},
+		{0, 1},
+		{50 * time.Millisecond, 1},
+		{250 * time.Millisecond, 2},
+		{250 * time.Millisecond, 3},
+		{time.Second, 5},
+		{time.Hour, 10},
+		{time.Hour, 0},
+	} {
+		var result, rationalResult Result
+		var err error
+		bucket, result = MustCompute(now.Add(step.offset), bucket, step.cost, opts)
+		rationalBucket, rationalResult, err = ComputeRational(now.Add(step.offset), rationalBucket, Rational{step.cost, 1}, rationalOpts)
+		assert.NoError(t, err)
+		assert.Equal(t, bucket, rationalBucket, i)
+		assert.Equal(t, result, rationalResult, i)
+	}
+}
+
+func TestComputeRationalExactness(t *testing.T) {
+	// the integer emission interval is rounded from 333.33ns to 333ns
+	opts := Options{
+		Burst:  3000,
+		Rate:   3,
+		Period: time.Microsecond,
+	}
+	rationalOpts := RationalOptions{
+		Burst:  Rational{3000, 1},
+		Rate:   Rational{3, 1},
+		Period: time.Microsecond,
+	}
+
+	// drain buckets
+	bucket, result := MustCompute(now, Bucket{}, 3000, opts)
+	assert.Equal(t, 999*time.Microsecond, result.ResetIn)
+	rationalBucket, result, err := ComputeRational(now, Bucket{}, Rational{3000, 1}, rationalOpts)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Millisecond, result.ResetIn)
+
+	// request full burst
+	_, result = MustCompute(now, bucket, 3000, opts)
+	assert.Equal(t, 999*time.Microsecond, result.RetryIn)
+	_, result, err = ComputeRational(now, rationalBucket, Rational{3000, 1}, rationalOpts)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Millisecond, result.RetryIn)
+
+	// unit requests every 10ns for 1ms agree with the integer path
+	opts.Burst = 3
+	rationalOpts.Burst = Rational{3, 1}
+	bucket, rationalBucket = Bucket{}, Bucket{}
+	var admitted, rationalAdmitted int64
+	for d := time.Duration(0); d < time.Millisecond; d += 10 * time.Nanosecond {
+		bucket, result = MustCompute(now.Add(d), bucket, 1, opts)
+		if !result.Limited {
+			admitted++
+		}
+		rationalBucket, result, err = ComputeRational(now.Add(d), rationalBucket, Rational{1, 1}, rationalOpts)
+		assert.NoError(t, err)
+		if !result.Limited {
+			rationalAdmitted++
+		}
+	}
+	assert.Equal(t, admitted, rationalAdmitted)
+}
+
+func TestComputeRationalStaleBucket(t *testing.T) {
+	opts := RationalOptions{
+		Burst:  Rational{10, 1},
+		Rate:   Rational{1000, 1},
+		Period: time.Second,
+	}
+
+	// a year old bucket is full
+	bucket := Bucket(now.Add(-365 * 24 * time.Hour))
+	newBucket, result, err := ComputeRational(now, bucket, Rational{1, 1}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, Result{
+		Remaining: 9,
+		ResetIn:   time.Millisecond,
+		Consumed:  1,
+		Balance:   9,
+	}, result)
+	assert.Equal(t, time.Millisecond, time.Time(newBucket).Sub(now))
+}
+
+func TestNearestDiv(t *testing.T) {
+	assert.Equal(t, int64(0), nearestDiv(0, 3))
+	assert.Equal(t, int64(0), nearestDiv(1, 3))
+	assert.Equal(t, int64(1), nearestDiv(2, 3))
+	assert.Equal(t, int64(1), nearestDiv(1, 2))
+	assert.Equal(t, int64(2), nearestDiv(5, 3))
+	assert.Equal(t, int64(math.MaxInt64/2), nearestDiv(math.MaxInt64-1, 2))
+	assert.Equal(t, int64(1), ceilDiv(1, 3))
+	assert.Equal(t, int64(0), ceilDiv(-1, 3))
+	assert.Equal(t, int64(2), ceilDiv(6, 3))
+}
+
+func TestComputeRationalErrors(t *testing.T) {
+	opts := RationalOptions{
+		Burst:  Rational{5, 2},
+		Rate:   Rational{5, 2},
+		Period: time.Second,
+	}
+
+	for _, invalid := range []RationalOptions{
+		{Burst: Rational{0, 1}, Rate: Rational{1, 1}, Period: time.Second},
+		{Burst: Rational{1, 0}, Rate: Rational{1, 1}, Period: time.Second},
+		{Burst: Rational{1, 1}, Rate: Rational{0, 1}, Period: time.Second},
+		{Burst: Rational{1, 1}, Rate: Rational{1, -1}, Period: time.Second},
+		{Burst: Rational{1, 1}, Rate: Rational{1, 1}},
+	} {
+		_, _, err := ComputeRational(now, Bucket{}, Rational{1, 1}, invalid)
+		assert.Equal(t, ErrInvalidParameter, err)
+	}
+
+	_, _, err := ComputeRational(now, Bucket{}, Rational{-1, 1}, opts)
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, _, err = ComputeRational(now, Bucket{}, Rational{1, 0}, opts)
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, _, err = ComputeRational(now, Bucket{}, Rational{3, 1}, opts)
+	assert.Equal(t, ErrCostHigherThanBurst, err)
+
+	_, _, err = ComputeRational(now, Bucket{}, Rational{1, 1}, RationalOptions{
+		Burst:  Rational{1, 3},
+		Rate:   Rational{math.MaxInt64, 1},
+		Period: time.Second,
+	})
+	assert.Equal(t, ErrOverflow, err)
+}
+
+func TestRationalFloat(t *testing.T) {
+	assert.Equal(t, 2.5, Rational{5, 2}.Float())
+}
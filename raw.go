@@ -0,0 +1,65 @@
+package gcra
+
+// RawResult is the result of a RawLimiter computation. Durations are expressed
+// in the units of the limiter.
+type RawResult struct {
+	Limited   bool
+	Remaining int64
+	RetryIn   int64
+	ResetIn   int64
+}
+
+// RawLimiter performs the GCRA using plain integer units. The raw functions
+// do not assume any unit, so time may be provided in any unit, e.g. from a
+// custom monotonic counter, as long as the period uses the same unit. A TAT of
+// zero represents a full bucket for all times that are not negative.
+type RawLimiter struct {
+	Burst  int64
+	Rate   int64
+	Period int64
+}
+
+// Generate will return a TAT representing a bucket that contains the specified
+// amount of tokens at the provided time.
+func (l RawLimiter) Generate(now, count int64) (int64, error) {
+	// check arguments
+	err := l.check(count)
+	if err != nil {
+		return 0, err
+	}
+
+	return GenerateRaw(now, count, l.Burst, l.Rate, l.Period), nil
+}
+
+// Compute will perform the GCRA and return the new TAT. Cost may be zero to
+// query the bucket.
+func (l RawLimiter) Compute(tat, now, cost int64) (int64, RawResult, error) {
+	// check arguments
+	err := l.check(cost)
+	if err != nil {
+		return tat, RawResult{}, err
+	}
+
+	// compute GCRA
+	newTAT, limited, remaining, retryIn, resetIn := ComputeRaw(tat, now, l.Burst, l.Rate, l.Period, cost)
+
+	return newTAT, RawResult{
+		Limited:   limited,
+		Remaining: remaining,
+		RetryIn:   retryIn,
+		ResetIn:   resetIn,
+	}, nil
+}
+
+func (l RawLimiter) check(cost int64) error {
+	// check arguments
+	if cost < 0 || l.Burst <= 0 || l.Rate <= 0 || l.Period <= 0 {
+		return ErrInvalidParameter
+	} else if cost > l.Burst {
+		return ErrCostHigherThanBurst
+	} else if roundDiv(l.Period, l.Rate) <= 0 {
+		return ErrZeroInterval
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package gcra
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRawLimiter(t *testing.T) {
+	// 10 tokens per 1000 ticks of a custom clock
+	l := RawLimiter{
+		Burst:  10,
+		Rate:   10,
+		Period: 1000,
+	}
+
+	tat, err := l.Generate(5000, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5500), tat)
+
+	tat, result, err := l.Compute(tat, 5000, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6000), tat)
+	assert.Equal(t, RawResult{
+		Remaining: 0,
+		ResetIn:   1000,
+	}, result)
+
+	tat, result, err = l.Compute(tat, 5000, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6000), tat)
+	assert.Equal(t, RawResult{
+		Limited: true,
+		RetryIn: 100,
+		ResetIn: 1000,
+	}, result)
+
+	tat, result, err = l.Compute(tat, 5250, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6200), tat)
+	assert.Equal(t, RawResult{
+		Remaining: 1,
+		ResetIn:   950,
+	}, result)
+
+	tat, result, err = l.Compute(0, 100, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), tat)
+	assert.Equal(t, RawResult{
+		Remaining: 10,
+	}, result)
+}
+
+func TestRawLimiterErrors(t *testing.T) {
+	_, err := RawLimiter{Burst: 0, Rate: 1, Period: 1}.Generate(0, 0)
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, _, err = RawLimiter{Burst: 1, Rate: 1, Period: 1}.Compute(0, 0, 2)
+	assert.Equal(t, ErrCostHigherThanBurst, err)
+
+	_, _, err = RawLimiter{Burst: 1, Rate: 1, Period: 1}.Compute(0, 0, -1)
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, _, err = RawLimiter{Burst: 1, Rate: 10, Period: 1}.Compute(0, 0, 1)
+	assert.Equal(t, ErrZeroInterval, err)
+}
@@ -0,0 +1,76 @@
+package gcra
+
+import "time"
+
+// FromRemaining will return a bucket that has the specified amount of remaining
+// tokens at the provided time. It is like Generate but clamps the remaining
+// tokens to the range [0, burst]. Invalid options yield a zero bucket.
+//
+// As the TAT encodes the remaining tokens in multiples of the emission
+// interval, FromRemaining and Remaining round-trip exactly at the same time.
+// At other times, Remaining rounds to the nearest token.
+func FromRemaining(now time.Time, remaining int64, opts Options) Bucket {
+	// handle unlimited
+	if opts.Burst == Unlimited {
+		bucket, _ := Generate(now, 0, opts)
+		return bucket
+	}
+
+	// generate bucket
+	bucket, err := Generate(now, clamp(remaining, 0, opts.burst(false)), opts)
+	if err != nil {
+		return Bucket{}
+	}
+
+	return bucket
+}
+
+// Remaining returns the remaining tokens of the bucket at the provided time as
+// reported by a zero cost query. Invalid options yield zero.
+func (b Bucket) Remaining(now time.Time, opts Options) int64 {
+	// query bucket
+	_, result, err := Compute(now, b, 0, opts)
+	if err != nil {
+		return 0
+	}
+
+	return result.Remaining
+}
+
+// TimeToRemaining returns the instant at which the bucket has at least the
+// target amount of remaining tokens, i.e. the earliest time a request with a
+// cost of target is admitted. If the target is already satisfied, now is
+// returned. The target is clamped to the range [0, burst]. As the remaining
+// tokens are rounded to the nearest token, the instant lies up to half an
+// emission interval before the TAT has moved back by the missing tokens.
+// Invalid options and unlimited buckets yield now.
+func TimeToRemaining(now time.Time, bucket Bucket, target int64, opts Options) time.Time {
+	// check options
+	burst := opts.burst(false)
+	if opts.Burst == Unlimited || burst <= 0 || !opts.valid() {
+		return now
+	}
+
+	// get resolution and emission interval
+	res := opts.resolution()
+	interval := roundDiv(int64(opts.EmissionInterval()), int64(res))
+	if interval <= 0 {
+		return now
+	}
+
+	// calculate TAT
+	bucket = opts.initial(now, bucket)
+	if time.Time(bucket).IsZero() {
+		return now
+	}
+	tat := toUnits(time.Time(bucket), res)
+
+	// compute instant (the TAT moves back by one interval per token and the
+	// difference is rounded to the nearest token)
+	at := tat - interval*(burst-clamp(target, 0, burst)) - (interval-1)/2
+	if at <= toUnits(now, res) {
+		return now
+	}
+
+	return fromUnits(at, res)
+}
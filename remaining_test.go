@@ -0,0 +1,81 @@
+package gcra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromRemaining(t *testing.T) {
+	for _, opts := range []Options{
+		{Burst: 10, Rate: 10, Period: time.Second},
+		{Burst: 7, Rate: 3, Period: time.Second},
+		{Burst: 100, Rate: 7, Period: 999 * time.Millisecond},
+		{Burst: 5, Rate: 1, Period: time.Minute, Resolution: time.Second},
+	} {
+		for r := int64(0); r <= opts.Burst; r++ {
+			assert.Equal(t, r, FromRemaining(now, r, opts).Remaining(now, opts), "%s r=%d", opts, r)
+		}
+	}
+
+	opts := Options{Burst: 10, Rate: 10, Period: time.Second}
+	assert.Equal(t, int64(10), FromRemaining(now, 20, opts).Remaining(now, opts))
+	assert.Equal(t, int64(0), FromRemaining(now, -1, opts).Remaining(now, opts))
+	assert.Equal(t, int64(5), FromRemaining(now, 2, opts).Remaining(now.Add(300*time.Millisecond), opts))
+
+	assert.Equal(t, Bucket{}, FromRemaining(now, 1, Options{}))
+	assert.Equal(t, int64(0), Bucket{}.Remaining(now, Options{}))
+	assert.Equal(t, int64(10), Bucket{}.Remaining(now, opts))
+}
+
+func TestTimeToRemaining(t *testing.T) {
+	opts := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+	}
+
+	bucket := MustGenerate(now, 2, opts)
+
+	// remaining tokens are rounded to the nearest token
+	half := 50*time.Millisecond - time.Nanosecond
+
+	for _, item := range []struct {
+		target int64
+		delay  time.Duration
+	}{
+		{target: 0, delay: 0},
+		{target: 2, delay: 0},
+		{target: 3, delay: 100*time.Millisecond - half},
+		{target: 5, delay: 300*time.Millisecond - half},
+		{target: 8, delay: 600*time.Millisecond - half},
+		{target: 10, delay: 800*time.Millisecond - half},
+		{target: 20, delay: 800*time.Millisecond - half},
+	} {
+		at := TimeToRemaining(now, bucket, item.target, opts)
+		assert.Equal(t, item.delay, at.Sub(now), item.target)
+
+		if item.delay > 0 && item.target <= opts.Burst {
+			assert.Equal(t, item.target, bucket.Remaining(at, opts))
+
+			_, result, err := Compute(at, bucket, item.target, opts)
+			assert.NoError(t, err)
+			assert.False(t, result.Limited)
+
+			_, result, err = Compute(at.Add(-time.Nanosecond), bucket, item.target, opts)
+			assert.NoError(t, err)
+			assert.True(t, result.Limited)
+		}
+	}
+
+	assert.Equal(t, now, TimeToRemaining(now, Bucket{}, 10, opts))
+	assert.Equal(t, now, TimeToRemaining(now, Bucket{}, 10, Options{}))
+
+	opts.NoReset = true
+	assert.Equal(t, now, TimeToRemaining(now, Bucket{}, 10, opts))
+
+	opts.NoReset = false
+	opts.WarmUp = 500 * time.Millisecond
+	assert.Equal(t, 300*time.Millisecond-half, TimeToRemaining(now, Bucket{}, 8, opts).Sub(now))
+}
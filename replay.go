@@ -0,0 +1,58 @@
+package gcra
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrUnsortedEvents is returned if replayed events are not sorted by time.
+var ErrUnsortedEvents = errors.New("unsorted events")
+
+// ErrNotMonotone is returned by CheckMonotone if the remaining tokens increase.
+var ErrNotMonotone = errors.New("not monotone")
+
+// Event is a single request to be replayed.
+type Event struct {
+	Time time.Time
+	Cost int64
+}
+
+// Replay will compute the provided events in order against a single initially
+// full bucket and return one result per event. The events must be sorted by
+// time.
+func Replay(events []Event, opts Options) ([]Result, error) {
+	// check order
+	for i := 1; i < len(events); i++ {
+		if events[i].Time.Before(events[i-1].Time) {
+			return nil, ErrUnsortedEvents
+		}
+	}
+
+	// compute events
+	var bucket Bucket
+	results := make([]Result, 0, len(events))
+	for _, event := range events {
+		var result Result
+		var err error
+		bucket, result, err = Compute(event.Time, bucket, event.Cost, opts)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// CheckMonotone will verify that the remaining tokens never increase across the
+// provided results. This must hold for results computed at the same point in
+// time with positive costs.
+func CheckMonotone(results []Result) error {
+	for i := 1; i < len(results); i++ {
+		if results[i].Remaining > results[i-1].Remaining {
+			return fmt.Errorf("%w: remaining increased from %d to %d at %d", ErrNotMonotone, results[i-1].Remaining, results[i].Remaining, i)
+		}
+	}
+	return nil
+}
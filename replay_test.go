@@ -0,0 +1,98 @@
+package gcra
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplay(t *testing.T) {
+	opts := Options{
+		Burst:  2,
+		Rate:   1,
+		Period: time.Second,
+	}
+
+	results, err := Replay([]Event{
+		{Time: now, Cost: 1},
+		{Time: now, Cost: 1},
+		{Time: now, Cost: 1},
+		{Time: now.Add(500 * time.Millisecond), Cost: 1},
+		{Time: now.Add(time.Second), Cost: 1},
+		{Time: now.Add(time.Second), Cost: 1},
+		{Time: now.Add(10 * time.Second), Cost: 2},
+		{Time: now.Add(10 * time.Second), Cost: 0},
+	}, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []Result{
+		{Limited: false, Remaining: 1, ResetIn: time.Second, Consumed: 1, Balance: 1},
+		{Limited: false, Remaining: 0, ResetIn: 2 * time.Second, Consumed: 1, Balance: 0},
+		{Limited: true, Remaining: 0, RetryIn: time.Second, ResetIn: 2 * time.Second, Balance: 0, Reason: InsufficientTokens},
+		{Limited: true, Remaining: 1, RetryIn: 500 * time.Millisecond, ResetIn: 1500 * time.Millisecond, Balance: 1, Reason: InsufficientTokens},
+		{Limited: false, Remaining: 0, ResetIn: 2 * time.Second, Consumed: 1, Balance: 0},
+		{Limited: true, Remaining: 0, RetryIn: time.Second, ResetIn: 2 * time.Second, Balance: 0, Reason: InsufficientTokens},
+		{Limited: false, Remaining: 0, ResetIn: 2 * time.Second, Consumed: 2, Balance: 0},
+		{Limited: true, Remaining: 0, ResetIn: 2 * time.Second, Balance: 0, Reason: EmptyQuery},
+	}, results)
+
+	results, err = Replay(nil, opts)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestReplayErrors(t *testing.T) {
+	opts := Options{
+		Burst:  2,
+		Rate:   1,
+		Period: time.Second,
+	}
+
+	_, err := Replay([]Event{
+		{Time: now.Add(time.Second), Cost: 1},
+		{Time: now, Cost: 1},
+	}, opts)
+	assert.Equal(t, ErrUnsortedEvents, err)
+
+	_, err = Replay([]Event{
+		{Time: now, Cost: 3},
+	}, opts)
+	assert.Equal(t, ErrCostHigherThanBurst, err)
+}
+
+func TestCheckMonotone(t *testing.T) {
+	assert.NoError(t, CheckMonotone(nil))
+	assert.NoError(t, CheckMonotone([]Result{{Remaining: 3}, {Remaining: 3}, {Remaining: 1}, {Remaining: 0}}))
+
+	err := CheckMonotone([]Result{{Remaining: 3}, {Remaining: 2}, {Remaining: 3}})
+	assert.True(t, errors.Is(err, ErrNotMonotone))
+	assert.Equal(t, "not monotone: remaining increased from 2 to 3 at 2", err.Error())
+}
+
+func TestMonotoneProperty(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 1000; i++ {
+		opts := Options{
+			Burst:  1 + random.Int63n(50),
+			Rate:   1 + random.Int63n(100),
+			Period: time.Duration(1 + random.Int63n(int64(time.Minute))),
+		}
+		if opts.EmissionInterval() == 0 {
+			continue
+		}
+
+		bucket := Bucket(time.Unix(0, now.UnixNano()+random.Int63n(int64(opts.Window())+1)))
+		_, result := MustCompute(now, bucket, 0, opts)
+
+		results := []Result{result}
+		for j := 0; j < 100; j++ {
+			bucket, result = MustCompute(now, bucket, 1+random.Int63n(opts.Burst), opts)
+			results = append(results, result)
+		}
+
+		assert.NoError(t, CheckMonotone(results), opts.String())
+	}
+}
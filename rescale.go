@@ -0,0 +1,62 @@
+package gcra
+
+import (
+	"math"
+	"time"
+)
+
+// Rescale will rescale the TAT of a bucket that has been encoded using the
+// unit from as unix nanoseconds to the unit to. This is useful to migrate
+// buckets that have been persisted in a different unit, e.g. unix
+// milliseconds. A zero bucket yields a zero bucket.
+func Rescale(bucket Bucket, from, to time.Duration) Bucket {
+	// check bucket and units
+	if time.Time(bucket).IsZero() || from <= 0 || to <= 0 || from == to {
+		return bucket
+	}
+
+	// rescale value
+	value := time.Time(bucket).UnixNano()
+	if from > to && from%to == 0 {
+		value *= int64(from / to)
+	} else if to > from && to%from == 0 {
+		value /= int64(to / from)
+	} else {
+		value = int64(math.Round(float64(value) * float64(from) / float64(to)))
+	}
+
+	return Bucket(time.Unix(0, value))
+}
+
+// likelyUnits are the units considered by LikelyUnit.
+var likelyUnits = []time.Duration{
+	time.Nanosecond,
+	time.Microsecond,
+	time.Millisecond,
+	time.Second,
+}
+
+// LikelyUnit guesses the unit in which the TAT of a bucket has been encoded as
+// unix nanoseconds by choosing the unit that places the TAT closest to now.
+// The guess assumes that the TAT is reasonably close to now. Zero or negative
+// TATs yield zero.
+func LikelyUnit(bucket Bucket, now time.Time) time.Duration {
+	// get value
+	value := time.Time(bucket).UnixNano()
+	if time.Time(bucket).IsZero() || value <= 0 {
+		return 0
+	}
+
+	// find unit with the smallest distance in orders of magnitude
+	var unit time.Duration
+	best := math.Inf(1)
+	for _, u := range likelyUnits {
+		distance := math.Abs(math.Log10(float64(value) * float64(u) / float64(now.UnixNano())))
+		if distance < best {
+			best = distance
+			unit = u
+		}
+	}
+
+	return unit
+}
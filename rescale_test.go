@@ -0,0 +1,37 @@
+package gcra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRescale(t *testing.T) {
+	tat := now.Add(1500 * time.Millisecond)
+
+	legacy := Bucket(time.Unix(0, tat.UnixMilli()))
+	bucket := Rescale(legacy, time.Millisecond, time.Nanosecond)
+	assert.Equal(t, tat, time.Time(bucket).UTC())
+	assert.Equal(t, legacy, Rescale(bucket, time.Nanosecond, time.Millisecond))
+
+	assert.Equal(t, tat.Truncate(time.Second), time.Time(Rescale(Bucket(time.Unix(0, tat.Unix())), time.Second, time.Nanosecond)).UTC())
+	assert.Equal(t, Bucket(time.Unix(0, 10)), Rescale(Bucket(time.Unix(0, 4)), 5, 2))
+
+	assert.Equal(t, Bucket{}, Rescale(Bucket{}, time.Millisecond, time.Nanosecond))
+	assert.Equal(t, legacy, Rescale(legacy, time.Millisecond, time.Millisecond))
+}
+
+func TestLikelyUnit(t *testing.T) {
+	tat := now.Add(time.Minute)
+
+	assert.Equal(t, time.Nanosecond, LikelyUnit(Bucket(tat), now))
+	assert.Equal(t, time.Microsecond, LikelyUnit(Bucket(time.Unix(0, tat.UnixMicro())), now))
+	assert.Equal(t, time.Millisecond, LikelyUnit(Bucket(time.Unix(0, tat.UnixMilli())), now))
+	assert.Equal(t, time.Second, LikelyUnit(Bucket(time.Unix(0, tat.Unix())), now))
+
+	legacy := Bucket(time.Unix(0, tat.UnixMilli()))
+	assert.Equal(t, tat, time.Time(Rescale(legacy, LikelyUnit(legacy, now), time.Nanosecond)).UTC())
+
+	assert.Equal(t, time.Duration(0), LikelyUnit(Bucket{}, now))
+}
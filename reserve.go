@@ -0,0 +1,87 @@
+package gcra
+
+import "time"
+
+// Reservation represents tokens reserved by Reserve.
+type Reservation struct {
+	// Delay is the duration after which the reserved tokens may be used.
+	Delay time.Duration
+
+	at     time.Time
+	res    time.Duration
+	refund int64
+}
+
+// Reserve will reserve the specified amount of tokens in the bucket. Unlike
+// Compute, the tokens are always reserved and the returned reservation
+// reports the delay after which they may be used. The reservation may be
+// cancelled to refund the tokens. Durations are not clamped and requests are
+// never rejected early.
+func Reserve(now time.Time, bucket Bucket, cost int64, opts Options) (Reservation, Bucket, error) {
+	// disable modifiers that do not apply to reservations
+	opts.ClampDurations = false
+	opts.EarlyRejectAt = 0
+
+	// compute GCRA
+	newBucket, result, err := Compute(now, bucket, cost, opts)
+	if err != nil {
+		return Reservation{}, bucket, err
+	}
+
+	// handle unlimited
+	if opts.Burst == Unlimited {
+		return Reservation{at: now}, bucket, nil
+	}
+
+	// get resolution and emission interval
+	res := opts.resolution()
+	interval := roundDiv(int64(opts.EmissionInterval()), int64(res))
+
+	// get increment (including the base cost)
+	charged := cost
+	if cost > 0 {
+		charged += opts.BaseCost
+	}
+	increment := mulAdd(interval, charged, 0)
+
+	// handle admitted
+	if !result.Limited {
+		return Reservation{
+			at:     now,
+			res:    res,
+			refund: increment,
+		}, newBucket, nil
+	}
+
+	// advance TAT beyond the burst by borrowing the missing tokens
+	borrow := ceilDiv(int64(result.RetryIn/res), interval) + 1
+	newBucket, _, err = compute(now, bucket, cost, opts, false, borrow)
+	if err != nil {
+		return Reservation{}, bucket, err
+	}
+
+	return Reservation{
+		Delay:  result.RetryIn,
+		at:     now.Add(result.RetryIn),
+		res:    res,
+		refund: increment,
+	}, newBucket, nil
+}
+
+// Cancel will refund the reserved tokens to the provided bucket. Like with
+// x/time/rate, tokens are only refunded if the reservation is cancelled before
+// or at the time it becomes usable.
+func (r Reservation) Cancel(now time.Time, bucket Bucket) Bucket {
+	// check if expired
+	if r.refund == 0 || now.After(r.at) {
+		return bucket
+	}
+
+	// refund tokens
+	tat := mulAdd(r.refund, -1, toUnits(time.Time(bucket), r.res))
+	if nowUnits := toUnits(now, r.res); nowUnits > tat {
+		tat = nowUnits
+	}
+
+	return Bucket(fromUnits(tat, r.res))
+}
@@ -0,0 +1,149 @@
+package gcra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReserve(t *testing.T) {
+	opts := Options{
+		Burst:  4,
+		Rate:   1,
+		Period: time.Second,
+	}
+
+	bucket := MustGenerate(now, 2, opts)
+
+	reservation, newBucket, err := Reserve(now, bucket, 2, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), reservation.Delay)
+	assert.Equal(t, Bucket(time.Unix(0, now.Add(4*time.Second).UnixNano())), newBucket)
+
+	assert.Equal(t, bucket, reservation.Cancel(now, newBucket))
+
+	reservation2, newBucket2, err := Reserve(now, newBucket, 3, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 3*time.Second, reservation2.Delay)
+	assert.Equal(t, Bucket(time.Unix(0, now.Add(7*time.Second).UnixNano())), newBucket2)
+
+	_, result := MustCompute(now, newBucket2, 0, opts)
+	assert.True(t, result.Limited)
+
+	assert.Equal(t, newBucket, reservation2.Cancel(now.Add(time.Second), newBucket2))
+	assert.Equal(t, newBucket2, reservation2.Cancel(now.Add(4*time.Second), newBucket2))
+	assert.Equal(t, newBucket2, reservation.Cancel(now.Add(time.Second), newBucket2))
+}
+
+func TestReserveCancelFull(t *testing.T) {
+	opts := Options{
+		Burst:  4,
+		Rate:   1,
+		Period: time.Second,
+	}
+
+	reservation, bucket, err := Reserve(now, Bucket{}, 1, opts)
+	assert.NoError(t, err)
+
+	bucket = reservation.Cancel(now, bucket)
+	assert.Equal(t, Bucket(time.Unix(0, now.UnixNano())), bucket)
+
+	_, result := MustCompute(now, bucket, 0, opts)
+	assert.Equal(t, Result{
+		Limited:   false,
+		Remaining: 4,
+		Balance:   4,
+	}, result)
+}
+
+func TestReserveBaseCost(t *testing.T) {
+	opts := Options{
+		Burst:    10,
+		Rate:     10,
+		Period:   time.Second,
+		BaseCost: 2,
+	}
+
+	// admitted reservation is charged like Compute
+	reservation, bucket, err := Reserve(now, Bucket{}, 1, opts)
+	assert.NoError(t, err)
+	expected, _ := MustCompute(now, Bucket{}, 1, opts)
+	assert.Equal(t, expected, bucket)
+	assert.Equal(t, 300*time.Millisecond, time.Time(bucket).Sub(now))
+	assert.Equal(t, Bucket(time.Unix(0, now.UnixNano())), reservation.Cancel(now, bucket))
+
+	// delayed reservation
+	bucket = MustGenerate(now, 0, opts)
+	reservation, newBucket, err := Reserve(now, bucket, 1, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 300*time.Millisecond, reservation.Delay)
+	assert.Equal(t, 1300*time.Millisecond, time.Time(newBucket).Sub(now))
+	assert.Equal(t, bucket, reservation.Cancel(now, newBucket))
+}
+
+func TestReserveResolution(t *testing.T) {
+	opts := Options{
+		Burst:      2,
+		Rate:       1,
+		Period:     1234567 * time.Microsecond,
+		Resolution: time.Millisecond,
+	}
+
+	// drain bucket
+	bucket, result := MustCompute(now, Bucket{}, 2, opts)
+	assert.False(t, result.Limited)
+	assert.Equal(t, 2470*time.Millisecond, time.Time(bucket).Sub(now.Truncate(time.Millisecond)))
+
+	// reservations advance in rounded intervals
+	reservation, newBucket, err := Reserve(now, bucket, 1, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 1235*time.Millisecond, time.Time(newBucket).Sub(time.Time(bucket)))
+	assert.Equal(t, 1235*time.Millisecond, reservation.Delay)
+	assert.Equal(t, bucket, reservation.Cancel(now, newBucket))
+
+	// admitted reservations match compute
+	bucket = MustGenerate(now, 1, opts)
+	reservation, newBucket, err = Reserve(now, bucket, 1, opts)
+	assert.NoError(t, err)
+	expected, _ := MustCompute(now, bucket, 1, opts)
+	assert.Equal(t, expected, newBucket)
+	assert.Equal(t, bucket, reservation.Cancel(now, newBucket))
+}
+
+func TestReserveUnlimited(t *testing.T) {
+	reservation, bucket, err := Reserve(now, Bucket{}, 10, Options{
+		Burst:  Unlimited,
+		Rate:   1,
+		Period: time.Second,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, Reservation{at: now}, reservation)
+	assert.Equal(t, Bucket{}, reservation.Cancel(now, bucket))
+}
+
+func TestReserveErrors(t *testing.T) {
+	_, _, err := Reserve(now, Bucket{}, 2, Options{Burst: 1, Rate: 1, Period: 1})
+	assert.Equal(t, ErrCostHigherThanBurst, err)
+}
+
+func TestReserveWarmUp(t *testing.T) {
+	opts := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+		WarmUp: 500 * time.Millisecond,
+	}
+
+	// admitted
+	reservation, bucket, err := Reserve(now, Bucket{}, 5, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), reservation.Delay)
+	assert.Equal(t, time.Second, time.Time(bucket).Sub(now))
+
+	// delayed
+	reservation, bucket, err = Reserve(now, Bucket{}, 8, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 300*time.Millisecond, reservation.Delay)
+	assert.Equal(t, 1300*time.Millisecond, time.Time(bucket).Sub(now))
+}
@@ -0,0 +1,31 @@
+package gcra
+
+import (
+	"math"
+	"time"
+)
+
+// Round returns a copy of the result with RetryIn rounded up to a multiple of
+// the specified granularity so that clients never retry early and ResetIn
+// rounded to the nearest multiple. All other fields are left intact. If the
+// granularity is less than or equal to zero, the result is returned unchanged.
+func (r Result) Round(d time.Duration) Result {
+	// check granularity
+	if d <= 0 {
+		return r
+	}
+
+	// round up retry (saturating values that cannot be represented)
+	if rem := r.RetryIn % d; rem > 0 {
+		if r.RetryIn > math.MaxInt64-(d-rem) {
+			r.RetryIn = math.MaxInt64 / d * d
+		} else {
+			r.RetryIn += d - rem
+		}
+	}
+
+	// round reset
+	r.ResetIn = r.ResetIn.Round(d)
+
+	return r
+}
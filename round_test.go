@@ -0,0 +1,54 @@
+package gcra
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultRound(t *testing.T) {
+	result := Result{
+		Limited:   true,
+		Remaining: 2,
+		RetryIn:   1200 * time.Millisecond,
+		ResetIn:   2400 * time.Millisecond,
+		Consumed:  0,
+		Balance:   -1,
+		Reason:    InsufficientTokens,
+	}
+
+	assert.Equal(t, Result{
+		Limited:   true,
+		Remaining: 2,
+		RetryIn:   2 * time.Second,
+		ResetIn:   2 * time.Second,
+		Balance:   -1,
+		Reason:    InsufficientTokens,
+	}, result.Round(time.Second))
+
+	result.RetryIn = 1201 * time.Millisecond
+	result.ResetIn = 2650 * time.Millisecond
+	assert.Equal(t, Result{
+		Limited:   true,
+		Remaining: 2,
+		RetryIn:   1300 * time.Millisecond,
+		ResetIn:   2700 * time.Millisecond,
+		Balance:   -1,
+		Reason:    InsufficientTokens,
+	}, result.Round(100*time.Millisecond))
+
+	// exact multiples
+	result.RetryIn = time.Second
+	result.ResetIn = 3 * time.Second
+	rounded := result.Round(time.Second)
+	assert.Equal(t, time.Second, rounded.RetryIn)
+	assert.Equal(t, 3*time.Second, rounded.ResetIn)
+
+	// zero and overflow
+	assert.Equal(t, result, result.Round(0))
+	assert.Equal(t, Result{}, Result{}.Round(time.Second))
+	rounded = Result{RetryIn: math.MaxInt64}.Round(time.Second)
+	assert.Equal(t, math.MaxInt64/time.Second*time.Second, rounded.RetryIn)
+}
@@ -0,0 +1,19 @@
+package gcra
+
+import "hash/fnv"
+
+// Shard returns the shard in the range [0, shards) for the specified key. The
+// assignment uses the FNV-1a hash and is stable across processes. It returns
+// zero if shards is not positive.
+func Shard(key string, shards int) int {
+	// check shards
+	if shards <= 0 {
+		return 0
+	}
+
+	// hash key
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+
+	return int(h.Sum64() % uint64(shards))
+}
@@ -0,0 +1,30 @@
+package gcra
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShard(t *testing.T) {
+	assert.Equal(t, Shard("foo", 16), Shard("foo", 16))
+	assert.Equal(t, 0, Shard("foo", 1))
+	assert.Equal(t, 0, Shard("foo", 0))
+	assert.Equal(t, 0, Shard("foo", -1))
+
+	// stable across processes
+	assert.Equal(t, 7, Shard("foo", 16))
+	assert.Equal(t, 10, Shard("bar", 16))
+
+	// reasonably uniform
+	counts := make([]int, 8)
+	for i := 0; i < 80000; i++ {
+		shard := Shard("key-"+strconv.Itoa(i), 8)
+		assert.True(t, shard >= 0 && shard < 8)
+		counts[shard]++
+	}
+	for _, count := range counts {
+		assert.InDelta(t, 10000, count, 500)
+	}
+}
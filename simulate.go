@@ -0,0 +1,65 @@
+package gcra
+
+import "time"
+
+// SimResult summarizes a simulation computed by Simulate.
+type SimResult struct {
+	// Admitted and Rejected count the admitted and rejected arrivals.
+	Admitted int64
+	Rejected int64
+
+	// TotalWait is the sum of the durations rejected arrivals would have had
+	// to wait until being admitted.
+	TotalWait time.Duration
+
+	// FinalBucket is the bucket after all arrivals.
+	FinalBucket Bucket
+}
+
+// AdmissionRate returns the fraction of admitted arrivals.
+func (r SimResult) AdmissionRate() float64 {
+	total := r.Admitted + r.Rejected
+	if total == 0 {
+		return 0
+	}
+	return float64(r.Admitted) / float64(total)
+}
+
+// AverageWait returns the average wait of rejected arrivals.
+func (r SimResult) AverageWait() time.Duration {
+	if r.Rejected == 0 {
+		return 0
+	}
+	return r.TotalWait / time.Duration(r.Rejected)
+}
+
+// Simulate will compute the provided arrivals with the specified cost in order
+// against a single initially full bucket and summarize the results. The
+// arrivals must be sorted by time.
+func Simulate(arrivals []time.Time, cost int64, opts Options) (SimResult, error) {
+	// check order
+	for i := 1; i < len(arrivals); i++ {
+		if arrivals[i].Before(arrivals[i-1]) {
+			return SimResult{}, ErrUnsortedEvents
+		}
+	}
+
+	// compute arrivals
+	var sim SimResult
+	for _, arrival := range arrivals {
+		var result Result
+		var err error
+		sim.FinalBucket, result, err = Compute(arrival, sim.FinalBucket, cost, opts)
+		if err != nil {
+			return SimResult{}, err
+		}
+		if result.Limited {
+			sim.Rejected++
+			sim.TotalWait += result.RetryIn
+		} else {
+			sim.Admitted++
+		}
+	}
+
+	return sim, nil
+}
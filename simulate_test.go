@@ -0,0 +1,62 @@
+package gcra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func constantArrivals(start time.Time, n int, interval time.Duration) []time.Time {
+	arrivals := make([]time.Time, 0, n)
+	for i := 0; i < n; i++ {
+		arrivals = append(arrivals, start.Add(time.Duration(i)*interval))
+	}
+	return arrivals
+}
+
+func TestSimulate(t *testing.T) {
+	opts := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+	}
+
+	// below sustained rate
+	sim, err := Simulate(constantArrivals(now, 1000, 200*time.Millisecond), 1, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000), sim.Admitted)
+	assert.Equal(t, int64(0), sim.Rejected)
+	assert.Equal(t, 1.0, sim.AdmissionRate())
+	assert.Equal(t, time.Duration(0), sim.AverageWait())
+
+	// at sustained rate
+	sim, err = Simulate(constantArrivals(now, 1000, 100*time.Millisecond), 1, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000), sim.Admitted)
+	assert.Equal(t, 1.0, sim.AdmissionRate())
+
+	// above sustained rate
+	sim, err = Simulate(constantArrivals(now, 1000, 50*time.Millisecond), 1, opts)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.5, sim.AdmissionRate(), 0.02)
+	assert.Equal(t, int64(1000), sim.Admitted+sim.Rejected)
+	assert.True(t, sim.AverageWait() > 0)
+	assert.True(t, sim.AverageWait() <= opts.EmissionInterval())
+
+	expected := MustGenerate(now.Add(999*50*time.Millisecond), 0, opts)
+	assert.True(t, time.Time(sim.FinalBucket).Sub(time.Time(expected)) < opts.EmissionInterval())
+
+	// empty
+	sim, err = Simulate(nil, 1, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, SimResult{}, sim)
+	assert.Equal(t, 0.0, sim.AdmissionRate())
+
+	// errors
+	_, err = Simulate([]time.Time{now, now.Add(-1)}, 1, opts)
+	assert.Equal(t, ErrUnsortedEvents, err)
+
+	_, err = Simulate([]time.Time{now}, 11, opts)
+	assert.Equal(t, ErrCostHigherThanBurst, err)
+}
@@ -0,0 +1,27 @@
+package gcra
+
+import "time"
+
+// OptionsForSLA returns options that admit all requests of a client that sends
+// at most n requests within any rolling window. The options use a burst of n
+// and regenerate the burst over the window, so that an additional request
+// within a drained window is limited. The emission interval is truncated to
+// the nanosecond, which only makes the options more permissive.
+func OptionsForSLA(n int64, window time.Duration) (Options, error) {
+	// check arguments
+	if n <= 0 || window <= 0 {
+		return Options{}, ErrInvalidParameter
+	}
+
+	// compute interval
+	interval := window / time.Duration(n)
+	if interval <= 0 {
+		return Options{}, ErrZeroInterval
+	}
+
+	return Options{
+		Burst:  n,
+		Rate:   1,
+		Period: interval,
+	}, nil
+}
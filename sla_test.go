@@ -0,0 +1,63 @@
+package gcra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptionsForSLA(t *testing.T) {
+	opts, err := OptionsForSLA(10, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, Options{
+		Burst:  10,
+		Rate:   1,
+		Period: 100 * time.Millisecond,
+	}, opts)
+
+	for _, item := range []struct {
+		n      int64
+		window time.Duration
+	}{
+		{n: 10, window: time.Second},
+		{n: 7, window: time.Second},
+		{n: 3, window: time.Minute},
+		{n: 100, window: 999 * time.Millisecond},
+	} {
+		opts, err := OptionsForSLA(item.n, item.window)
+		assert.NoError(t, err)
+
+		// back-to-back requests
+		var bucket Bucket
+		var times []time.Time
+		for i := int64(0); i < item.n; i++ {
+			var result Result
+			at := now.Add(time.Duration(i))
+			bucket, result = MustCompute(at, bucket, 1, opts)
+			assert.False(t, result.Limited)
+			times = append(times, at)
+		}
+
+		// an additional request is limited
+		_, result := MustCompute(now.Add(time.Duration(item.n)), bucket, 1, opts)
+		assert.True(t, result.Limited)
+
+		// sliding requests that keep n requests per window
+		for i := 0; i < int(item.n)*3; i++ {
+			at := times[len(times)-int(item.n)].Add(item.window)
+			bucket, result = MustCompute(at, bucket, 1, opts)
+			assert.False(t, result.Limited, "n=%d window=%s i=%d", item.n, item.window, i)
+			times = append(times, at)
+		}
+	}
+
+	_, err = OptionsForSLA(0, time.Second)
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, err = OptionsForSLA(1, 0)
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, err = OptionsForSLA(10, 5)
+	assert.Equal(t, ErrZeroInterval, err)
+}
@@ -0,0 +1,56 @@
+package gcra
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// UnixMilli returns the TAT of the bucket as unix milliseconds. The TAT is
+// truncated to milliseconds, which may make the bucket up to a millisecond
+// more lenient. A zero bucket yields zero.
+func (b Bucket) UnixMilli() int64 {
+	if time.Time(b).IsZero() {
+		return 0
+	}
+	return time.Time(b).UnixMilli()
+}
+
+// BucketFromUnixMilli returns a bucket with the TAT represented by the
+// provided unix milliseconds. Zero yields a zero bucket.
+func BucketFromUnixMilli(ms int64) Bucket {
+	if ms == 0 {
+		return Bucket{}
+	}
+	return Bucket(time.UnixMilli(ms))
+}
+
+// Scan implements the sql.Scanner interface. It reads the TAT from an integer
+// of unix milliseconds, which may also be provided as text by some drivers. A
+// NULL value yields a zero bucket.
+func (b *Bucket) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*b = Bucket{}
+	case int64:
+		*b = BucketFromUnixMilli(v)
+	case []byte:
+		return b.Scan(string(v))
+	case string:
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("cannot scan %q into bucket: %w", v, err)
+		}
+		*b = BucketFromUnixMilli(ms)
+	default:
+		return fmt.Errorf("cannot scan %T into bucket", src)
+	}
+	return nil
+}
+
+// Value implements the driver.Valuer interface. It writes the TAT as an integer
+// of unix milliseconds.
+func (b Bucket) Value() (driver.Value, error) {
+	return b.UnixMilli(), nil
+}
@@ -0,0 +1,76 @@
+package gcra
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var _ sql.Scanner = &Bucket{}
+var _ driver.Valuer = Bucket{}
+
+func TestBucketUnixMilli(t *testing.T) {
+	bucket := Bucket(now.Add(1500*time.Millisecond + 999*time.Microsecond))
+	assert.Equal(t, now.UnixMilli()+1500, bucket.UnixMilli())
+	assert.Equal(t, now.Add(1500*time.Millisecond), time.Time(BucketFromUnixMilli(bucket.UnixMilli())).UTC())
+
+	assert.Equal(t, int64(0), Bucket{}.UnixMilli())
+	assert.Equal(t, Bucket{}, BucketFromUnixMilli(0))
+}
+
+func TestBucketSQL(t *testing.T) {
+	bucket := Bucket(time.UnixMilli(now.UnixMilli() + 1234))
+
+	value, err := bucket.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, now.UnixMilli()+1234, value)
+
+	var scanned Bucket
+	err = scanned.Scan(value)
+	assert.NoError(t, err)
+	assert.Equal(t, bucket, scanned)
+
+	value, err = Bucket{}.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), value)
+
+	err = scanned.Scan(value)
+	assert.NoError(t, err)
+	assert.Equal(t, Bucket{}, scanned)
+
+	scanned = bucket
+	err = scanned.Scan(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Bucket{}, scanned)
+
+	err = scanned.Scan("foo")
+	assert.Error(t, err)
+
+	err = scanned.Scan([]byte("foo"))
+	assert.Error(t, err)
+
+	err = scanned.Scan(1.5)
+	assert.Error(t, err)
+}
+
+func TestBucketSQLText(t *testing.T) {
+	bucket := Bucket(time.UnixMilli(now.UnixMilli() + 1234))
+
+	var scanned Bucket
+	err := scanned.Scan(strconv.FormatInt(now.UnixMilli()+1234, 10))
+	assert.NoError(t, err)
+	assert.Equal(t, bucket, scanned)
+
+	scanned = Bucket{}
+	err = scanned.Scan([]byte(strconv.FormatInt(now.UnixMilli()+1234, 10)))
+	assert.NoError(t, err)
+	assert.Equal(t, bucket, scanned)
+
+	err = scanned.Scan([]byte("0"))
+	assert.NoError(t, err)
+	assert.Equal(t, Bucket{}, scanned)
+}
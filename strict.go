@@ -0,0 +1,47 @@
+package gcra
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrLimited is returned by ComputeStrict if the request has been limited.
+var ErrLimited = errors.New("limited")
+
+// LimitedError is returned by ComputeStrict if the request has been limited.
+// It matches ErrLimited using errors.Is.
+type LimitedError struct {
+	RetryIn time.Duration
+	Result  Result
+}
+
+// Error implements the error interface.
+func (e *LimitedError) Error() string {
+	return fmt.Sprintf("limited: retry in %s", e.RetryIn)
+}
+
+// Unwrap returns ErrLimited.
+func (e *LimitedError) Unwrap() error {
+	return ErrLimited
+}
+
+// ComputeStrict will perform the GCRA like Compute but return a LimitedError
+// if the request has been limited.
+func ComputeStrict(now time.Time, bucket Bucket, cost int64, opts Options) (Bucket, Result, error) {
+	// compute
+	bucket, result, err := Compute(now, bucket, cost, opts)
+	if err != nil {
+		return bucket, result, err
+	}
+
+	// check limited
+	if result.Limited {
+		return bucket, result, &LimitedError{
+			RetryIn: result.RetryIn,
+			Result:  result,
+		}
+	}
+
+	return bucket, result, nil
+}
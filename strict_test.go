@@ -0,0 +1,50 @@
+package gcra
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeStrict(t *testing.T) {
+	opts := Options{
+		Burst:  2,
+		Rate:   1,
+		Period: time.Second,
+	}
+
+	bucket, result, err := ComputeStrict(now, Bucket{}, 2, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, Result{
+		Remaining: 0,
+		ResetIn:   2 * time.Second,
+		Consumed:  2,
+	}, result)
+
+	newBucket, result, err := ComputeStrict(now, bucket, 1, opts)
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrLimited))
+	assert.Equal(t, "limited: retry in 1s", err.Error())
+	assert.Equal(t, bucket, newBucket)
+	assert.Equal(t, Result{
+		Limited:   true,
+		Remaining: 0,
+		RetryIn:   time.Second,
+		ResetIn:   2 * time.Second,
+		Reason:    InsufficientTokens,
+	}, result)
+
+	var limitedErr *LimitedError
+	assert.True(t, errors.As(err, &limitedErr))
+	assert.Equal(t, time.Second, limitedErr.RetryIn)
+	assert.Equal(t, result, limitedErr.Result)
+
+	_, expected := MustCompute(now, bucket, 1, opts)
+	assert.Equal(t, expected, result)
+
+	_, _, err = ComputeStrict(now, Bucket{}, 3, opts)
+	assert.Equal(t, ErrCostHigherThanBurst, err)
+	assert.False(t, errors.Is(err, ErrLimited))
+}
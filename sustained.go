@@ -0,0 +1,51 @@
+package gcra
+
+import "time"
+
+// Sustained returns the options of the bucket that enforces the sustained
+// rate. The bucket allows SustainedRate tokens per SustainedPeriod and uses
+// the resolution, base cost and duration clamping of the options.
+func (o Options) Sustained() Options {
+	return Options{
+		Burst:          o.SustainedRate,
+		Rate:           o.SustainedRate,
+		Period:         o.SustainedPeriod,
+		Resolution:     o.Resolution,
+		ClampDurations: o.ClampDurations,
+		BaseCost:       o.BaseCost,
+	}
+}
+
+// ComputeSustained will perform the GCRA like Compute using the bucket and
+// additionally enforce the sustained rate of the options using the sustained
+// bucket. The request is only admitted and both buckets are only updated if
+// neither bucket limits the request. The results are combined using Aggregate.
+// If no sustained rate is configured, the sustained bucket is returned
+// unchanged.
+func ComputeSustained(now time.Time, bucket, sustained Bucket, cost int64, opts Options) (Bucket, Bucket, Result, error) {
+	// compute bucket
+	newBucket, result, err := Compute(now, bucket, cost, opts)
+	if err != nil {
+		return bucket, sustained, Result{}, err
+	}
+
+	// handle unlimited and missing sustained rate
+	if opts.Burst == Unlimited || opts.SustainedRate == 0 {
+		return newBucket, sustained, result, nil
+	}
+
+	// compute sustained bucket
+	newSustained, sustainedResult, err := Compute(now, sustained, cost, opts.Sustained())
+	if err != nil {
+		return bucket, sustained, Result{}, err
+	}
+
+	// combine results, limited requests still initialize new buckets so that
+	// the warm up is only applied once
+	result = Aggregate(result, sustainedResult)
+	if result.Limited {
+		return opts.initial(now, bucket), opts.Sustained().initial(now, sustained), result, nil
+	}
+
+	return newBucket, newSustained, result, nil
+}
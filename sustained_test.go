@@ -0,0 +1,105 @@
+package gcra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeSustained(t *testing.T) {
+	opts := Options{
+		Burst:           10,
+		Rate:            10,
+		Period:          time.Second,
+		SustainedRate:   30,
+		SustainedPeriod: time.Minute,
+	}
+
+	// burst is allowed instantly
+	var bucket, sustained Bucket
+	var result Result
+	var err error
+	for i := 0; i < 10; i++ {
+		bucket, sustained, result, err = ComputeSustained(now, bucket, sustained, 1, opts)
+		assert.NoError(t, err)
+		assert.False(t, result.Limited)
+	}
+	assert.Equal(t, int64(0), result.Remaining)
+
+	// burst bucket limits
+	newBucket, newSustained, result, err := ComputeSustained(now, bucket, sustained, 1, opts)
+	assert.NoError(t, err)
+	assert.True(t, result.Limited)
+	assert.Equal(t, 100*time.Millisecond, result.RetryIn)
+	assert.Equal(t, bucket, newBucket)
+	assert.Equal(t, sustained, newSustained)
+
+	// sustained throughput is capped over a minute (the remaining 20
+	// sustained tokens plus 30 regenerated tokens instead of 600)
+	var admitted int64
+	for d := time.Duration(0); d < time.Minute; d += 10 * time.Millisecond {
+		bucket, sustained, result, err = ComputeSustained(now.Add(d), bucket, sustained, 1, opts)
+		assert.NoError(t, err)
+		if !result.Limited {
+			admitted++
+		}
+	}
+	assert.Equal(t, int64(50), admitted)
+
+	// sustained bucket limits
+	_, _, result, err = ComputeSustained(now.Add(time.Minute), bucket, sustained, 1, opts)
+	assert.NoError(t, err)
+	assert.True(t, result.Limited)
+	assert.Equal(t, 2*time.Second, result.RetryIn)
+	assert.Equal(t, int64(0), result.Remaining)
+}
+
+func TestComputeSustainedDisabled(t *testing.T) {
+	opts := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+	}
+
+	bucket, sustained, result, err := ComputeSustained(now, Bucket{}, Bucket{}, 4, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, Bucket{}, sustained)
+
+	expectedBucket, expectedResult := MustCompute(now, Bucket{}, 4, opts)
+	assert.Equal(t, expectedBucket, bucket)
+	assert.Equal(t, expectedResult, result)
+}
+
+func TestComputeSustainedErrors(t *testing.T) {
+	_, _, _, err := ComputeSustained(now, Bucket{}, Bucket{}, 1, Options{Burst: 10, Rate: 1, Period: time.Second, SustainedRate: 5})
+	assert.Equal(t, ErrInvalidParameter, err)
+
+	_, _, _, err = ComputeSustained(now, Bucket{}, Bucket{}, 8, Options{Burst: 10, Rate: 1, Period: time.Second, SustainedRate: 5, SustainedPeriod: time.Minute})
+	assert.Equal(t, ErrCostHigherThanBurst, err)
+}
+
+func TestComputeSustainedWarmUp(t *testing.T) {
+	opts := Options{
+		Burst:           10,
+		Rate:            10,
+		Period:          time.Second,
+		WarmUp:          time.Second,
+		SustainedRate:   100,
+		SustainedPeriod: time.Second,
+	}
+
+	// the warm up is only applied to the first request
+	var bucket, sustained Bucket
+	var admitted int
+	for i := 0; i < 100; i++ {
+		var result Result
+		var err error
+		bucket, sustained, result, err = ComputeSustained(now.Add(time.Duration(i)*100*time.Millisecond), bucket, sustained, 1, opts)
+		assert.NoError(t, err)
+		if !result.Limited {
+			admitted++
+		}
+	}
+	assert.Equal(t, 99, admitted)
+}
@@ -0,0 +1,31 @@
+package gcra
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrInvalidVersion is returned if a bucket version cannot be parsed.
+var ErrInvalidVersion = errors.New("invalid version")
+
+// Version returns a compact and stable version string for the bucket that
+// may be used as an ETag or for compare-and-swap operations. The version is
+// the lowercase hex encoding of the TAT as returned by Encode. Buckets with
+// the same TAT yield the same version and different TATs different versions.
+// A zero bucket yields "0".
+func (b Bucket) Version() string {
+	return strconv.FormatUint(uint64(b.Encode()), 16)
+}
+
+// ParseVersion returns the bucket represented by the provided version. Only
+// versions as returned by Version are accepted.
+func ParseVersion(version string) (Bucket, error) {
+	// parse version
+	v, err := strconv.ParseUint(version, 16, 64)
+	if err != nil || strconv.FormatUint(v, 16) != version {
+		return Bucket{}, fmt.Errorf("%w: %q", ErrInvalidVersion, version)
+	}
+
+	return Decode(int64(v)), nil
+}
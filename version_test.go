@@ -0,0 +1,48 @@
+package gcra
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucketVersion(t *testing.T) {
+	opts := Options{
+		Burst:  10,
+		Rate:   10,
+		Period: time.Second,
+	}
+
+	assert.Equal(t, "0", Bucket{}.Version())
+	assert.Equal(t, "16cce0ca2b6d7e00", Bucket(now).Version())
+
+	for _, bucket := range []Bucket{
+		{},
+		Bucket(time.Unix(0, now.UnixNano())),
+		MustGenerate(now, 3, opts),
+		Bucket(time.Unix(0, 1)),
+		Bucket(time.Unix(0, -1)),
+		Bucket(time.Unix(0, time.Date(2200, 1, 1, 0, 0, 0, 1, time.UTC).UnixNano())),
+	} {
+		decoded, err := ParseVersion(bucket.Version())
+		assert.NoError(t, err)
+		assert.Equal(t, bucket, decoded)
+	}
+
+	// equality
+	a := MustGenerate(now, 3, opts)
+	b := MustGenerate(now, 3, opts)
+	c := MustGenerate(now, 4, opts)
+	assert.Equal(t, a.Version(), b.Version())
+	assert.NotEqual(t, a.Version(), c.Version())
+	assert.NotEqual(t, Bucket(now).Version(), Bucket(now.Add(time.Nanosecond)).Version())
+}
+
+func TestParseVersionErrors(t *testing.T) {
+	for _, version := range []string{"", "x", "-1", "00", "0A", "10000000000000000"} {
+		_, err := ParseVersion(version)
+		assert.True(t, errors.Is(err, ErrInvalidVersion), version)
+	}
+}
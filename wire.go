@@ -0,0 +1,58 @@
+package gcra
+
+import "time"
+
+// Wire is a plain representation of a bucket and result that uses integer
+// nanoseconds instead of time.Time and time.Duration values. It maps directly
+// to language-neutral formats like protocol buffers.
+type Wire struct {
+	TAT       int64
+	Limited   bool
+	Remaining int64
+	RetryIn   int64
+	ResetIn   int64
+	Consumed  int64
+	Balance   int64
+	Reason    Reason
+}
+
+// ToWire will convert the provided bucket and result to their wire
+// representation. A zero bucket is represented by a zero TAT.
+func ToWire(bucket Bucket, result Result) Wire {
+	// get TAT
+	var tat int64
+	if !time.Time(bucket).IsZero() {
+		tat = time.Time(bucket).UnixNano()
+	}
+
+	return Wire{
+		TAT:       tat,
+		Limited:   result.Limited,
+		Remaining: result.Remaining,
+		RetryIn:   int64(result.RetryIn),
+		ResetIn:   int64(result.ResetIn),
+		Consumed:  result.Consumed,
+		Balance:   result.Balance,
+		Reason:    result.Reason,
+	}
+}
+
+// FromWire will convert the provided wire representation back to a bucket and
+// result. A zero TAT yields a zero bucket.
+func FromWire(wire Wire) (Bucket, Result) {
+	// get bucket
+	var bucket Bucket
+	if wire.TAT != 0 {
+		bucket = Bucket(time.Unix(0, wire.TAT))
+	}
+
+	return bucket, Result{
+		Limited:   wire.Limited,
+		Remaining: wire.Remaining,
+		RetryIn:   time.Duration(wire.RetryIn),
+		ResetIn:   time.Duration(wire.ResetIn),
+		Consumed:  wire.Consumed,
+		Balance:   wire.Balance,
+		Reason:    wire.Reason,
+	}
+}
@@ -0,0 +1,57 @@
+package gcra
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWire(t *testing.T) {
+	opts := Options{
+		Burst:  4,
+		Rate:   1,
+		Period: time.Second,
+	}
+
+	bucket, result := MustCompute(now, Bucket{}, 1, opts)
+	wire := ToWire(bucket, result)
+	assert.Equal(t, Wire{
+		TAT:       now.Add(time.Second).UnixNano(),
+		Limited:   false,
+		Remaining: 3,
+		RetryIn:   0,
+		ResetIn:   int64(time.Second),
+		Consumed:  1,
+		Balance:   3,
+	}, wire)
+
+	bucket2, result2 := FromWire(wire)
+	assert.Equal(t, bucket, bucket2)
+	assert.Equal(t, result, result2)
+
+	bucket, result = MustCompute(now, bucket, 4, opts)
+	wire = ToWire(bucket, result)
+	assert.Equal(t, Wire{
+		TAT:       now.Add(time.Second).UnixNano(),
+		Limited:   true,
+		Remaining: 3,
+		RetryIn:   int64(time.Second),
+		ResetIn:   int64(time.Second),
+		Balance:   3,
+		Reason:    InsufficientTokens,
+	}, wire)
+
+	bucket2, result2 = FromWire(wire)
+	assert.Equal(t, bucket, bucket2)
+	assert.Equal(t, result, result2)
+}
+
+func TestWireZero(t *testing.T) {
+	wire := ToWire(Bucket{}, Result{})
+	assert.Equal(t, Wire{}, wire)
+
+	bucket, result := FromWire(wire)
+	assert.Equal(t, Bucket{}, bucket)
+	assert.Equal(t, Result{}, result)
+}